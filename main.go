@@ -1,7 +1,50 @@
 package main
 
-import "fmt"
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ory/dockertest"
+	"github.com/strangelove-ventures/ibc-test-framework/reaper"
+)
 
 func main() {
-	fmt.Println("IBC Test Framework")
+	if len(os.Args) < 2 {
+		fmt.Println("IBC Test Framework")
+		fmt.Println("usage: ibc-test-framework reap [-older-than duration] [-run-id id] [-test name]")
+		return
+	}
+
+	switch os.Args[1] {
+	case "reap":
+		if err := reap(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		os.Exit(2)
+	}
+}
+
+// reap removes the docker resources left behind by test runs that did not clean up after themselves
+func reap(args []string) error {
+	fs := flag.NewFlagSet("reap", flag.ExitOnError)
+	olderThan := fs.Duration("older-than", time.Hour, "only reap resources created longer ago than this")
+	runID := fs.String("run-id", "", "only reap resources created by this test run")
+	testName := fs.String("test", "", "only reap resources created by this test")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		return err
+	}
+	res, err := reaper.Reap(pool.Client, reaper.Filter{RunID: *runID, TestName: *testName, OlderThan: *olderThan})
+	fmt.Printf("removed %d containers, %d networks and %d volumes\n",
+		len(res.Containers), len(res.Networks), len(res.Volumes))
+	return err
 }
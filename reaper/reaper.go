@@ -0,0 +1,148 @@
+// Package reaper removes the docker resources created by the test framework
+package reaper
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ory/dockertest/docker"
+)
+
+const (
+	// LabelTest is the label holding the name of the test that created a resource
+	LabelTest = "horcrux-test"
+	// LabelRunID is the label holding the id of the go test run that created a resource
+	LabelRunID = "ibc-test-framework.run-id"
+	// LabelCreated is the label holding the unix time a resource was created at
+	LabelCreated = "ibc-test-framework.created"
+)
+
+// NewRunID returns a random id identifying a go test run
+func NewRunID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Labels returns the labels to set on a resource created by the given test of a run
+func Labels(runID, testName string) map[string]string {
+	return map[string]string{
+		LabelTest:    testName,
+		LabelRunID:   runID,
+		LabelCreated: strconv.FormatInt(time.Now().Unix(), 10),
+	}
+}
+
+// Filter selects the framework resources to reap, empty fields match every resource
+type Filter struct {
+	RunID     string
+	TestName  string
+	OlderThan time.Duration
+}
+
+// labelFilters returns the docker label filters matching the run and test of the filter
+func (f Filter) labelFilters() []string {
+	filters := []string{LabelRunID}
+	if f.RunID != "" {
+		filters[0] = fmt.Sprintf("%s=%s", LabelRunID, f.RunID)
+	}
+	if f.TestName != "" {
+		filters = append(filters, fmt.Sprintf("%s=%s", LabelTest, f.TestName))
+	}
+	return filters
+}
+
+// matches returns true if the resource with the given labels is old enough to reap
+func (f Filter) matches(labels map[string]string, now time.Time) bool {
+	if f.OlderThan == 0 {
+		return true
+	}
+	created, err := strconv.ParseInt(labels[LabelCreated], 10, 64)
+	if err != nil {
+		return false
+	}
+	return now.Sub(time.Unix(created, 0)) >= f.OlderThan
+}
+
+// Result lists the resources removed by Reap
+type Result struct {
+	Containers []string
+	Networks   []string
+	Volumes    []string
+}
+
+// Reap force removes the containers, networks and volumes created by the framework that match the filter,
+// it keeps going when a resource fails to be removed and returns every failure in the error
+func Reap(client *docker.Client, f Filter) (Result, error) {
+	var res Result
+	var failures []string
+	now := time.Now()
+	labels := f.labelFilters()
+
+	containers, err := client.ListContainers(docker.ListContainersOptions{
+		All:     true,
+		Filters: map[string][]string{"label": labels},
+	})
+	if err != nil {
+		failures = append(failures, fmt.Sprintf("list containers: %v", err))
+	}
+	for _, c := range containers {
+		if !f.matches(c.Labels, now) {
+			continue
+		}
+		if err := client.RemoveContainer(docker.RemoveContainerOptions{ID: c.ID, Force: true, RemoveVolumes: true}); err != nil {
+			if _, ok := err.(*docker.NoSuchContainer); !ok {
+				failures = append(failures, fmt.Sprintf("remove container %s: %v", c.ID, err))
+			}
+			continue
+		}
+		res.Containers = append(res.Containers, c.ID)
+	}
+
+	networkFilters := docker.NetworkFilterOpts{"label": {}}
+	for _, l := range labels {
+		networkFilters["label"][l] = true
+	}
+	networks, err := client.FilteredListNetworks(networkFilters)
+	if err != nil {
+		failures = append(failures, fmt.Sprintf("list networks: %v", err))
+	}
+	for _, n := range networks {
+		if !f.matches(n.Labels, now) {
+			continue
+		}
+		if err := client.RemoveNetwork(n.ID); err != nil {
+			failures = append(failures, fmt.Sprintf("remove network %s: %v", n.Name, err))
+			continue
+		}
+		res.Networks = append(res.Networks, n.Name)
+	}
+
+	volumes, err := client.ListVolumes(docker.ListVolumesOptions{
+		Filters: map[string][]string{"label": labels},
+	})
+	if err != nil {
+		failures = append(failures, fmt.Sprintf("list volumes: %v", err))
+	}
+	for _, v := range volumes {
+		if !f.matches(v.Labels, now) {
+			continue
+		}
+		if err := client.RemoveVolume(v.Name); err != nil {
+			failures = append(failures, fmt.Sprintf("remove volume %s: %v", v.Name, err))
+			continue
+		}
+		res.Volumes = append(res.Volumes, v.Name)
+	}
+
+	if len(failures) > 0 {
+		return res, fmt.Errorf("failed to reap %d resources:\n%s", len(failures), strings.Join(failures, "\n"))
+	}
+	return res, nil
+}
@@ -0,0 +1,51 @@
+package reaper
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterLabelFilters(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"every run", Filter{}, []string{LabelRunID}},
+		{"run", Filter{RunID: "abc"}, []string{LabelRunID + "=abc"}},
+		{"test of every run", Filter{TestName: "TestFoo"}, []string{LabelRunID, LabelTest + "=TestFoo"}},
+		{"test of a run", Filter{RunID: "abc", TestName: "TestFoo"}, []string{LabelRunID + "=abc", LabelTest + "=TestFoo"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.filter.labelFilters())
+		})
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	now := time.Unix(1640000000, 0)
+	created := func(age time.Duration) map[string]string {
+		return map[string]string{LabelCreated: strconv.FormatInt(now.Add(-age).Unix(), 10)}
+	}
+	for _, tc := range []struct {
+		name   string
+		filter Filter
+		labels map[string]string
+		want   bool
+	}{
+		{"no age limit", Filter{}, created(time.Minute), true},
+		{"no age limit without created label", Filter{}, map[string]string{}, true},
+		{"older", Filter{OlderThan: time.Hour}, created(2 * time.Hour), true},
+		{"exactly as old", Filter{OlderThan: time.Hour}, created(time.Hour), true},
+		{"newer", Filter{OlderThan: time.Hour}, created(time.Minute), false},
+		{"missing created label", Filter{OlderThan: time.Hour}, map[string]string{}, false},
+		{"invalid created label", Filter{OlderThan: time.Hour}, map[string]string{LabelCreated: "yesterday"}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.filter.matches(tc.labels, now))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package test
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/pelletier/go-toml"
+	"github.com/stretchr/testify/require"
+)
+
+// BoolPtr returns a pointer to b for setting the optional fields of AppConfig
+func BoolPtr(b bool) *bool {
+	return &b
+}
+
+// Uint64Ptr returns a pointer to n for setting the optional fields of AppConfig
+func Uint64Ptr(n uint64) *uint64 {
+	return &n
+}
+
+// Uint32Ptr returns a pointer to n for setting the optional fields of AppConfig
+func Uint32Ptr(n uint32) *uint32 {
+	return &n
+}
+
+// AppConfigPath returns the path of the node's app.toml
+func (tn *TestNode) AppConfigPath() string {
+	return path.Join(tn.Dir(), "config", "app.toml")
+}
+
+// WriteAppConfig applies the chain's and then the node's AppConfig overrides to the app.toml written by init,
+// the file is left untouched when nothing is overridden
+func (tn *TestNode) WriteAppConfig() error {
+	if tn.chainAppConfig == (AppConfig{}) && tn.AppConfig == (AppConfig{}) {
+		return nil
+	}
+	tree, err := toml.LoadFile(tn.AppConfigPath())
+	if err != nil {
+		return err
+	}
+	tn.chainAppConfig.apply(tree)
+	tn.AppConfig.apply(tree)
+	bz, err := tree.Marshal()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(tn.AppConfigPath(), bz, 0644) //nolint
+}
+
+// apply sets the overridden values in an app.toml tree, sections and keys that are
+// not overridden, including ones specific to a chain binary, are left untouched
+func (c AppConfig) apply(tree *toml.Tree) {
+	if c.MinimumGasPrices != "" {
+		tree.Set("minimum-gas-prices", c.MinimumGasPrices)
+	}
+	if c.Pruning != "" {
+		tree.Set("pruning", c.Pruning)
+	}
+	if c.PruningKeepRecent != "" {
+		tree.Set("pruning-keep-recent", c.PruningKeepRecent)
+	}
+	if c.PruningInterval != "" {
+		tree.Set("pruning-interval", c.PruningInterval)
+	}
+	if c.APIEnable != nil {
+		tree.Set("api.enable", *c.APIEnable)
+	}
+	if c.GRPCEnable != nil {
+		tree.Set("grpc.enable", *c.GRPCEnable)
+	}
+	if c.SnapshotInterval != nil {
+		tree.Set("state-sync.snapshot-interval", *c.SnapshotInterval)
+	}
+	if c.SnapshotKeepRecent != nil {
+		tree.Set("state-sync.snapshot-keep-recent", uint64(*c.SnapshotKeepRecent))
+	}
+	if c.TelemetryEnable != nil {
+		tree.Set("telemetry.enabled", *c.TelemetryEnable)
+	}
+}
+
+func TestAppConfigApply(t *testing.T) {
+	tree, err := toml.Load(`
+minimum-gas-prices = ""
+pruning = "default"
+
+[api]
+enable = false
+
+[state-sync]
+snapshot-interval = 0
+
+[wasm]
+query_gas_limit = 300000
+`)
+	require.NoError(t, err)
+
+	AppConfig{
+		MinimumGasPrices: "0.01uatom",
+		Pruning:          "nothing",
+		APIEnable:        BoolPtr(true),
+		SnapshotInterval: Uint64Ptr(100),
+		TelemetryEnable:  BoolPtr(true),
+	}.apply(tree)
+
+	require.Equal(t, "0.01uatom", tree.Get("minimum-gas-prices"))
+	require.Equal(t, "nothing", tree.Get("pruning"))
+	require.Equal(t, true, tree.Get("api.enable"))
+	require.Equal(t, uint64(100), tree.Get("state-sync.snapshot-interval"))
+	require.Equal(t, true, tree.Get("telemetry.enabled"))
+	require.Equal(t, int64(300000), tree.Get("wasm.query_gas_limit"))
+
+	// a node override only replaces the fields it sets
+	AppConfig{Pruning: "everything"}.apply(tree)
+	require.Equal(t, "everything", tree.Get("pruning"))
+	require.Equal(t, "0.01uatom", tree.Get("minimum-gas-prices"))
+
+	_, err = tree.Marshal()
+	require.NoError(t, err)
+}
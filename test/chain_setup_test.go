@@ -1,9 +1,12 @@
 package test
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -20,12 +23,14 @@ import (
 	"github.com/avast/retry-go"
 	"github.com/cosmos/cosmos-sdk/client"
 	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	"github.com/cosmos/cosmos-sdk/simapp"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
 	"github.com/ory/dockertest"
 	"github.com/ory/dockertest/docker"
+	"github.com/spf13/viper"
 	"github.com/strangelove-ventures/horcrux/signer"
 	"github.com/stretchr/testify/require"
 	tmconfig "github.com/tendermint/tendermint/config"
@@ -40,27 +45,18 @@ var (
 	valKey = "validator"
 )
 
-func getGaiadChain() *ChainType {
-	return &ChainType{
-		Repository: "ghcr.io/strangelove-ventures/heighliner/gaia",
-		Version:    "v6.0.0-rocks",
-		Bin:        "gaiad",
-		Ports: map[docker.Port]struct{}{
-			"26656/tcp": {},
-			"26657/tcp": {},
-			"9090/tcp":  {},
-			"1337/tcp":  {},
-			"1234/tcp":  {},
-		},
-	}
-}
+const (
+	genesisAmount = 1000000000000
+	gentxAmount   = 100000000000
+)
 
 // CliContext creates a new Cosmos SDK client context
-// TODO: replace this with a lens client
 func (tn *TestNode) CliContext() client.Context {
 	return client.Context{
 		Client:            tn.Client,
 		ChainID:           tn.ChainID,
+		Codec:             tn.ec.Marshaler,
+		TxConfig:          tn.ec.TxConfig,
 		InterfaceRegistry: tn.ec.InterfaceRegistry,
 		Input:             os.Stdin,
 		Output:            os.Stdout,
@@ -72,7 +68,18 @@ func (tn *TestNode) CliContext() client.Context {
 // MakeTestNodes creates the test node objects required for bootstrapping tests
 func MakeTestNodes(count int, home, chainid string, chainType *ChainType,
 	pool *dockertest.Pool, t *testing.T) (out TestNodes) {
-	for i := 0; i < count; i++ {
+	chainTypes := make([]*ChainType, count)
+	for i := range chainTypes {
+		chainTypes[i] = chainType
+	}
+	return MakeTestNodesWithChainTypes(chainTypes, home, chainid, pool, t)
+}
+
+// MakeTestNodesWithChainTypes creates a test node for each chain type, which lets the nodes of one chain
+// run different versions of the chain
+func MakeTestNodesWithChainTypes(chainTypes []*ChainType, home, chainid string,
+	pool *dockertest.Pool, t *testing.T) (out TestNodes) {
+	for i, chainType := range chainTypes {
 		tn := &TestNode{Home: home, Index: i, Chain: chainType, ChainID: chainid,
 			Pool: pool, t: t, ec: simapp.MakeTestEncodingConfig()}
 		tn.MkDir()
@@ -83,11 +90,19 @@ func MakeTestNodes(count int, home, chainid string, chainType *ChainType,
 
 // StartNodeContainers is passed a chain id and arrays of validators and full nodes to configure
 func StartNodeContainers(t *testing.T, ctx context.Context, net *docker.Network, validators, fullnodes []*TestNode) {
+	StartNodeContainersWithGenesisHooks(t, ctx, net, validators, fullnodes, GenesisHooks{})
+}
+
+// StartNodeContainersWithGenesisHooks is StartNodeContainers with hooks that modify the genesis file
+// before and after the gentxs are collected
+func StartNodeContainersWithGenesisHooks(t *testing.T, ctx context.Context, net *docker.Network,
+	validators, fullnodes []*TestNode, hooks GenesisHooks) {
 	var eg errgroup.Group
 
 	// sign gentx for each validator
 	for _, v := range validators {
 		v := v
+		v.Validator = true
 		eg.Go(func() error { return v.InitValidatorFiles(ctx) })
 	}
 
@@ -105,10 +120,10 @@ func StartNodeContainers(t *testing.T, ctx context.Context, net *docker.Network,
 	validator0 := validators[0]
 	for i := 1; i < len(validators); i++ {
 		validatorN := validators[i]
-		n0key, err := validatorN.GetKey(valKey)
+		nAddr, err := validatorN.AccountAddress(valKey)
 		require.NoError(t, err)
 
-		require.NoError(t, validator0.AddGenesisAccount(ctx, n0key.GetAddress().String()))
+		require.NoError(t, validator0.AddGenesisAccount(ctx, nAddr, validatorN.genesisCoins()))
 		nNid, err := validatorN.NodeID()
 		require.NoError(t, err)
 		oldPath := path.Join(validatorN.Dir(), "config", "gentx", fmt.Sprintf("gentx-%s.json", nNid))
@@ -116,7 +131,9 @@ func StartNodeContainers(t *testing.T, ctx context.Context, net *docker.Network,
 		require.NoError(t, os.Rename(oldPath, newPath))
 	}
 	require.NoError(t, eg.Wait())
+	require.NoError(t, validator0.ModifyGenesis(hooks.PreCollectGentxs...))
 	require.NoError(t, validator0.CollectGentxs(ctx))
+	require.NoError(t, validator0.ModifyGenesis(hooks.PostCollectGentxs...))
 
 	genbz, err := ioutil.ReadFile(validator0.GenesisFilePath())
 	require.NoError(t, err)
@@ -133,6 +150,11 @@ func StartNodeContainers(t *testing.T, ctx context.Context, net *docker.Network,
 	for _, n := range nodes {
 		n := n
 		eg.Go(func() error {
+			if n.Cosmovisor != nil {
+				if err := n.SetupCosmovisor(ctx); err != nil {
+					return err
+				}
+			}
 			return n.CreateNodeContainer(net.ID, true)
 		})
 	}
@@ -144,7 +166,12 @@ func StartNodeContainers(t *testing.T, ctx context.Context, net *docker.Network,
 		n := n
 		t.Logf("{%s} => starting container...", n.Name())
 		eg.Go(func() error {
-			n.SetValidatorConfigAndPeers(peers)
+			if err := n.SetValidatorConfigAndPeers(peers); err != nil {
+				return err
+			}
+			if err := n.WriteAppConfig(); err != nil {
+				return err
+			}
 			return n.StartContainer(ctx)
 		})
 	}
@@ -255,7 +282,7 @@ func (hosts Hosts) WaitForAllToStart(t *testing.T, timeout int) {
 
 // Name is the hostname of the test node container
 func (tn *TestNode) Name() string {
-	return fmt.Sprintf("node-%d-%s", tn.Index, tn.t.Name())
+	return Hostname(fmt.Sprintf("node-%d-%s", tn.Index, tn.ChainID), tn.t)
 }
 
 // Dir is the directory where the test node files are stored
@@ -286,11 +313,11 @@ func (tn *TestNode) TMConfigPath() string {
 
 // Bind returns the home folder bind point for running the node
 func (tn *TestNode) Bind() []string {
-	return []string{fmt.Sprintf("%s:/home/.%s", tn.Dir(), tn.Chain.Bin)}
+	return []string{fmt.Sprintf("%s:%s", tn.Dir(), tn.NodeHome())}
 }
 
 func (tn *TestNode) NodeHome() string {
-	return fmt.Sprintf("/home/.%s", tn.Chain.Bin)
+	return tn.Chain.NodeHome()
 }
 
 // Keybase returns the keyring for a given node
@@ -302,23 +329,39 @@ func (tn *TestNode) Keybase() keyring.Keyring {
 	return kr
 }
 
-// SetValidatorConfigAndPeers modifies the config for a validator node to start a chain
-func (tn *TestNode) SetValidatorConfigAndPeers(peers string) {
-	// Pull default config
-	cfg := tmconfig.DefaultConfig()
-
-	// change config to include everything needed
-	stdconfigchanges(cfg, peers)
+// SetValidatorConfigAndPeers modifies the config for a validator node to start a chain,
+// the node's TMConfigMutators are applied after the standard changes
+func (tn *TestNode) SetValidatorConfigAndPeers(peers string) error {
+	return tn.ModifyTMConfig(func(cfg *tmconfig.Config) {
+		stdconfigchanges(cfg, peers)
+	})
+}
 
-	// overwrite with the new config
-	tmconfig.WriteConfigFile(tn.TMConfigPath(), cfg)
+// SetPrivValdidatorListen makes the node listen for a remote signer
+func (tn *TestNode) SetPrivValdidatorListen(peers string) error {
+	return tn.ModifyTMConfig(func(cfg *tmconfig.Config) {
+		cfg.BaseConfig.PrivValidatorListenAddr = "tcp://0.0.0.0:1234"
+		stdconfigchanges(cfg, peers)
+	})
 }
 
-func (tn *TestNode) SetPrivValdidatorListen(peers string) {
+// ModifyTMConfig loads the node's config.toml, applies the mutators followed by the node's
+// TMConfigMutators and writes it back
+func (tn *TestNode) ModifyTMConfig(mutators ...TMConfigMutator) error {
+	v := viper.New()
+	v.SetConfigFile(tn.TMConfigPath())
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
 	cfg := tmconfig.DefaultConfig()
-	cfg.BaseConfig.PrivValidatorListenAddr = "tcp://0.0.0.0:1234"
-	stdconfigchanges(cfg, peers) // Reapply the changes made to the config file in SetValidatorConfigAndPeers()
+	if err := v.Unmarshal(cfg); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", tn.TMConfigPath(), err)
+	}
+	for _, mutate := range append(mutators, tn.TMConfigMutators...) {
+		mutate(cfg)
+	}
 	tmconfig.WriteConfigFile(tn.TMConfigPath(), cfg)
+	return nil
 }
 
 func (tn *TestNode) getValSigningInfo() *slashingtypes.QuerySigningInfoResponse {
@@ -431,6 +474,7 @@ func (tn *TestNode) EnsureNoMissedBlocks() {
 	}
 }
 
+// stdconfigchanges are the default config.toml changes made to every node
 func stdconfigchanges(cfg *tmconfig.Config, peers string) {
 	// turn down blocktimes to make the chain faster
 	cfg.Consensus.TimeoutCommit = 3 * time.Second
@@ -450,39 +494,155 @@ func stdconfigchanges(cfg *tmconfig.Config, peers string) {
 	cfg.P2P.PersistentPeers = peers
 }
 
+// JobResult is the exit code and output of a job container
+type JobResult struct {
+	Container string
+	ExitCode  int
+	Stdout    string
+	Stderr    string
+}
+
 // NodeJob run a container for a specific job and block until the container exits
 // NOTE: on job containers generate random name
-func (tn *TestNode) NodeJob(ctx context.Context, cmd []string) (int, error) {
+func (tn *TestNode) NodeJob(ctx context.Context, cmd []string) (JobResult, error) {
+	return tn.ImageJob(ctx, tn.Chain.Image(), cmd)
+}
+
+// ImageJob is NodeJob with a container from another image, such as a different version of the node's chain
+func (tn *TestNode) ImageJob(ctx context.Context, image string, cmd []string) (JobResult, error) {
 	container := RandLowerCaseLetterString(10)
 	tn.t.Logf("{%s}[%s] -> '%s'", tn.Name(), container, strings.Join(cmd, " "))
-	cont, err := tn.Pool.Client.CreateContainer(docker.CreateContainerOptions{
+	res, err := runJobContainer(ctx, tn.Pool, docker.CreateContainerOptions{
 		Name: container,
 		Config: &docker.Config{
 			User:         getDockerUserString(),
 			Hostname:     container,
 			ExposedPorts: tn.Chain.Ports,
 			DNS:          []string{},
-			Image:        fmt.Sprintf("%s:%s", tn.Chain.Repository, tn.Chain.Version),
+			Image:        image,
 			Cmd:          cmd,
-			Labels:       map[string]string{"horcrux-test": tn.t.Name()},
+			Labels:       testLabels(tn.t),
 		},
 		HostConfig: &docker.HostConfig{
 			Binds:           tn.Bind(),
 			PublishAllPorts: true,
-			AutoRemove:      true,
 		},
 		NetworkingConfig: &docker.NetworkingConfig{
-			EndpointsConfig: map[string]*docker.EndpointConfig{},
+			EndpointsConfig: tn.jobEndpoints(),
 		},
 		Context: nil,
 	})
+	if err == nil && res.ExitCode != 0 {
+		tn.t.Logf("{%s}[%s] exited with code %d\nstdout:\n%s\nstderr:\n%s",
+			tn.Name(), container, res.ExitCode, res.Stdout, res.Stderr)
+	}
+	return res, err
+}
+
+// runJobContainer creates and starts a job container, blocks until it exits and reads its logs
+// before removing it, the container must not be created with AutoRemove
+func runJobContainer(ctx context.Context, pool *dockertest.Pool, opts docker.CreateContainerOptions) (JobResult, error) {
+	res := JobResult{Container: opts.Name, ExitCode: 1}
+	cont, err := pool.Client.CreateContainer(opts)
+	if err != nil {
+		return res, err
+	}
+	defer func() {
+		_ = pool.Client.RemoveContainer(docker.RemoveContainerOptions{ID: cont.ID, Force: true})
+	}()
+	if err := pool.Client.StartContainer(cont.ID, nil); err != nil {
+		return res, err
+	}
+	if res.ExitCode, err = pool.Client.WaitContainerWithContext(cont.ID, ctx); err != nil {
+		return res, err
+	}
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if err := pool.Client.Logs(docker.LogsOptions{
+		Context:      ctx,
+		Container:    cont.ID,
+		OutputStream: stdout,
+		ErrorStream:  stderr,
+		Stdout:       true,
+		Stderr:       true,
+	}); err != nil {
+		return res, err
+	}
+	res.Stdout, res.Stderr = stdout.String(), stderr.String()
+	return res, nil
+}
+
+// Exec runs a command inside the running node container through the docker exec api and returns its
+// stdout and stderr, a non-zero exit code is returned as an error that includes stderr
+func (tn *TestNode) Exec(ctx context.Context, cmd []string) (stdout, stderr []byte, err error) {
+	tn.t.Logf("{%s} exec -> '%s'", tn.Name(), strings.Join(cmd, " "))
+	return execInContainer(ctx, tn.Pool, tn.Container.ID, getDockerUserString(), cmd)
+}
+
+// execInContainer runs a command inside a running container as the given user
+func execInContainer(ctx context.Context, pool *dockertest.Pool, containerID, user string,
+	cmd []string) (stdout, stderr []byte, err error) {
+	exec, err := pool.Client.CreateExec(docker.CreateExecOptions{
+		Container:    containerID,
+		Cmd:          cmd,
+		User:         user,
+		AttachStdout: true,
+		AttachStderr: true,
+		Context:      ctx,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var outBuf, errBuf bytes.Buffer
+	if err := pool.Client.StartExec(exec.ID, docker.StartExecOptions{
+		OutputStream: &outBuf,
+		ErrorStream:  &errBuf,
+		Context:      ctx,
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	inspect, err := pool.Client.InspectExec(exec.ID)
 	if err != nil {
-		return 1, err
+		return outBuf.Bytes(), errBuf.Bytes(), err
 	}
-	if err := tn.Pool.Client.StartContainer(cont.ID, nil); err != nil {
-		return 1, err
+	if inspect.ExitCode != 0 {
+		return outBuf.Bytes(), errBuf.Bytes(), fmt.Errorf("exec '%s' in %s returned exit code %d: %s",
+			strings.Join(cmd, " "), containerID, inspect.ExitCode, strings.TrimSpace(errBuf.String()))
+	}
+	return outBuf.Bytes(), errBuf.Bytes(), nil
+}
+
+// ExecJSON runs a command inside the running node container and decodes its json output into out
+func (tn *TestNode) ExecJSON(ctx context.Context, cmd []string, out interface{}) error {
+	stdout, _, err := tn.Exec(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(stdout, out); err != nil {
+		return fmt.Errorf("failed to decode output of '%s': %w", strings.Join(cmd, " "), err)
+	}
+	return nil
+}
+
+// QueryCommand is a helper to build a query command with json output, to be run with ExecJSON
+func (tn *TestNode) QueryCommand(command ...string) []string {
+	command = append([]string{tn.Chain.Bin, "query"}, command...)
+	return append(command,
+		"--output", "json",
+		"--home", tn.NodeHome(),
+	)
+}
+
+// jobEndpoints attaches job containers to the test network once the node is on it
+// so that jobs can reach the running nodes by hostname
+func (tn *TestNode) jobEndpoints() map[string]*docker.EndpointConfig {
+	endpoints := map[string]*docker.EndpointConfig{}
+	if tn.NetworkID != "" {
+		endpoints[tn.NetworkID] = &docker.EndpointConfig{}
 	}
-	return tn.Pool.Client.WaitContainerWithContext(cont.ID, ctx)
+	return endpoints
 }
 
 // InitHomeFolder initializes a home folder for the given node
@@ -504,17 +664,33 @@ func (tn *TestNode) CreateKey(ctx context.Context, name string) error {
 	return handleNodeJobError(tn.NodeJob(ctx, command))
 }
 
-// AddGenesisAccount adds a genesis account for each key
-func (tn *TestNode) AddGenesisAccount(ctx context.Context, address string) error {
-	command := []string{tn.Chain.Bin, "add-genesis-account", address, "1000000000000stake",
+// CreateKeyWithMnemonic creates a key in the node's test keyring from the host and returns its mnemonic
+// so that the same key can be restored into a relayer
+func (tn *TestNode) CreateKeyWithMnemonic(name string) (keyring.Info, string, error) {
+	return tn.Keybase().NewMnemonic(name, keyring.English, sdk.FullFundraiserPath, "", hd.Secp256k1)
+}
+
+// AddressFromMnemonic derives the bech32 account address for a mnemonic with the given prefix
+func AddressFromMnemonic(mnemonic, prefix string) (string, error) {
+	derivedPriv, err := hd.Secp256k1.Derive()(mnemonic, "", sdk.FullFundraiserPath)
+	if err != nil {
+		return "", err
+	}
+	privKey := hd.Secp256k1.Generate()(derivedPriv)
+	return sdk.Bech32ifyAddressBytes(prefix, privKey.PubKey().Address())
+}
+
+// AddGenesisAccount adds a genesis account with the given comma separated coins
+func (tn *TestNode) AddGenesisAccount(ctx context.Context, address, coins string) error {
+	command := []string{tn.Chain.Bin, "add-genesis-account", address, coins,
 		"--home", tn.NodeHome(),
 	}
 	return handleNodeJobError(tn.NodeJob(ctx, command))
 }
 
-// Gentx generates the gentx for a given node
+// Gentx generates the gentx for a given node, self delegating in the chain's bond denom
 func (tn *TestNode) Gentx(ctx context.Context, name string) error {
-	command := []string{tn.Chain.Bin, "gentx", valKey, "100000000000stake",
+	command := []string{tn.Chain.Bin, "gentx", valKey, fmt.Sprintf("%d%s", gentxAmount, tn.Chain.Denom),
 		"--keyring-backend", "test",
 		"--home", tn.NodeHome(),
 		"--chain-id", tn.ChainID,
@@ -522,6 +698,46 @@ func (tn *TestNode) Gentx(ctx context.Context, name string) error {
 	return handleNodeJobError(tn.NodeJob(ctx, command))
 }
 
+// genesisCoins returns the coins the node's validator account is funded with at genesis,
+// which defaults to an amount of the chain's bond denom
+func (tn *TestNode) genesisCoins() string {
+	if tn.GenesisCoins != "" {
+		return tn.GenesisCoins
+	}
+	return fmt.Sprintf("%d%s", genesisAmount, tn.Chain.Denom)
+}
+
+// TxCommand returns the command for a transaction signed by a key in the node's keyring
+// and broadcast to the running node, waiting for the transaction to be included in a block
+func (tn *TestNode) TxCommand(keyName string, command ...string) []string {
+	command = append([]string{tn.Chain.Bin, "tx"}, command...)
+	return append(command,
+		"--from", keyName,
+		"--keyring-backend", "test",
+		"--node", fmt.Sprintf("tcp://%s:26657", tn.Name()),
+		"--chain-id", tn.ChainID,
+		"--gas-prices", tn.Chain.GasPrices,
+		"--broadcast-mode", "block",
+		"--yes",
+		"--home", tn.NodeHome(),
+	)
+}
+
+// BankSend sends coins from a key in the node's keyring to an address, broadcasting to the running node
+func (tn *TestNode) BankSend(ctx context.Context, keyName, toAddr, amount string) error {
+	command := tn.TxCommand(keyName, "bank", "send", keyName, toAddr, amount)
+	return handleNodeJobError(tn.NodeJob(ctx, command))
+}
+
+// AccountAddress returns the bech32 address of a key in the node's keyring using the chain's prefix
+func (tn *TestNode) AccountAddress(keyName string) (string, error) {
+	info, err := tn.GetKey(keyName)
+	if err != nil {
+		return "", err
+	}
+	return sdk.Bech32ifyAddressBytes(tn.Chain.Bech32Prefix, info.GetAddress())
+}
+
 // CollectGentxs runs collect gentxs on the node's home folders
 func (tn *TestNode) CollectGentxs(ctx context.Context) error {
 	command := []string{tn.Chain.Bin, "collect-gentxs",
@@ -530,17 +746,26 @@ func (tn *TestNode) CollectGentxs(ctx context.Context) error {
 	return handleNodeJobError(tn.NodeJob(ctx, command))
 }
 
+// startCommand returns the command the node container runs, which is wrapped with cosmovisor in cosmovisor mode
+func (tn *TestNode) startCommand() []string {
+	if tn.Cosmovisor != nil {
+		return []string{tn.CosmovisorPath(), "start", "--home", tn.NodeHome()}
+	}
+	return []string{tn.Chain.Bin, "start", "--home", tn.NodeHome()}
+}
+
 func (tn *TestNode) CreateNodeContainer(networkID string, rm bool) error {
 	cont, err := tn.Pool.Client.CreateContainer(docker.CreateContainerOptions{
 		Name: tn.Name(),
 		Config: &docker.Config{
 			User:         getDockerUserString(),
-			Cmd:          []string{tn.Chain.Bin, "start", "--home", tn.NodeHome()},
+			Cmd:          tn.startCommand(),
+			Env:          tn.startEnv(),
 			Hostname:     tn.Name(),
 			ExposedPorts: tn.Chain.Ports,
 			DNS:          []string{},
-			Image:        fmt.Sprintf("%s:%s", tn.Chain.Repository, tn.Chain.Version),
-			Labels:       map[string]string{"horcrux-test": tn.t.Name()},
+			Image:        tn.Chain.Image(),
+			Labels:       testLabels(tn.t),
 		},
 		HostConfig: &docker.HostConfig{
 			Binds:           tn.Bind(),
@@ -558,6 +783,7 @@ func (tn *TestNode) CreateNodeContainer(networkID string, rm bool) error {
 		return err
 	}
 	tn.Container = cont
+	tn.NetworkID = networkID
 	return nil
 }
 
@@ -569,18 +795,11 @@ func (tn *TestNode) StartContainer(ctx context.Context) error {
 	if err := tn.Pool.Client.StartContainer(tn.Container.ID, nil); err != nil {
 		return err
 	}
-
-	c, err := tn.Pool.Client.InspectContainer(tn.Container.ID)
-	if err != nil {
+	if err := tn.StreamLogs(); err != nil {
 		return err
 	}
-	tn.Container = c
-
-	port := GetHostPort(c, "26657/tcp")
-	tn.t.Logf("{%s} RPC => %s", tn.Name(), port)
 
-	err = tn.NewClient(fmt.Sprintf("tcp://%s", port))
-	if err != nil {
+	if err := tn.RefreshClient(); err != nil {
 		return err
 	}
 
@@ -600,19 +819,37 @@ func (tn *TestNode) StartContainer(ctx context.Context) error {
 	}, retry.DelayType(retry.BackOffDelay))
 }
 
+// RefreshClient inspects the node container and points the node's RPC client at the host port the RPC port
+// is currently published on, which changes when the container is started or its networks change
+func (tn *TestNode) RefreshClient() error {
+	c, err := tn.Pool.Client.InspectContainer(tn.Container.ID)
+	if err != nil {
+		return err
+	}
+	tn.Container = c
+
+	port := GetHostPort(c, "26657/tcp")
+	tn.t.Logf("{%s} RPC => %s", tn.Name(), port)
+
+	return tn.NewClient(fmt.Sprintf("tcp://%s", port))
+}
+
 // InitValidatorFiles creates the node files and signs a genesis transaction
 func (tn *TestNode) InitValidatorFiles(ctx context.Context) error {
 	if err := tn.InitHomeFolder(ctx); err != nil {
 		return err
 	}
+	if err := tn.ModifyGenesis(SetBondDenom(tn.Chain.Denom)); err != nil {
+		return err
+	}
 	if err := tn.CreateKey(ctx, valKey); err != nil {
 		return err
 	}
-	key, err := tn.GetKey(valKey)
+	address, err := tn.AccountAddress(valKey)
 	if err != nil {
 		return err
 	}
-	if err := tn.AddGenesisAccount(ctx, key.GetAddress().String()); err != nil {
+	if err := tn.AddGenesisAccount(ctx, address, tn.genesisCoins()); err != nil {
 		return err
 	}
 	return tn.Gentx(ctx, valKey)
@@ -622,12 +859,13 @@ func (tn *TestNode) InitFullNodeFiles(ctx context.Context) error {
 	return tn.InitHomeFolder(ctx)
 }
 
-func handleNodeJobError(i int, err error) error {
+func handleNodeJobError(res JobResult, err error) error {
 	if err != nil {
 		return err
 	}
-	if i != 0 {
-		return fmt.Errorf("container returned non-zero error code: %d", i)
+	if res.ExitCode != 0 {
+		return fmt.Errorf("container %s returned non-zero error code: %d\nstdout:\n%s\nstderr:\n%s",
+			res.Container, res.ExitCode, res.Stdout, res.Stderr)
 	}
 	return nil
 }
@@ -649,6 +887,33 @@ func (tn *TestNode) GetKey(name string) (info keyring.Info, err error) {
 	})
 }
 
+// maxHostnameLen is the maximum length of a container hostname
+const maxHostnameLen = 63
+
+// Hostname returns a container hostname made of the prefix and a short token of the test, subtest names
+// are too long to be part of a hostname, the prefix is cut to keep the hostname within 63 characters
+func Hostname(prefix string, t *testing.T) string {
+	sum := sha256.Sum256([]byte(t.Name()))
+	token := hex.EncodeToString(sum[:])[:10]
+	if max := maxHostnameLen - len(token) - 1; len(prefix) > max {
+		prefix = prefix[:max]
+	}
+	return fmt.Sprintf("%s-%s", prefix, token)
+}
+
+// DockerName replaces the characters that docker does not allow in container names,
+// such as the slashes in subtest names, with underscores
+func DockerName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
 // RandLowerCaseLetterString returns a lowercase letter string of given length
 func RandLowerCaseLetterString(length int) string {
 	chars := []rune("abcdefghijklmnopqrstuvwxyz")
@@ -763,3 +1028,14 @@ func getDockerUserString() string {
 	}
 	return usr
 }
+
+func TestHostname(t *testing.T) {
+	t.Run("rly_v6.0.0-rocks_v6.0.0-rocks", func(st *testing.T) {
+		name := Hostname("node-0-gaia-1", st)
+		require.Regexp(st, `^node-0-gaia-1-[0-9a-f]{10}$`, name)
+		require.NotEqual(st, Hostname("node-0-gaia-1", t), name)
+
+		long := Hostname(fmt.Sprintf("node-0-%s", strings.Repeat("chain", 20)), st)
+		require.Len(st, long, maxHostnameLen)
+	})
+}
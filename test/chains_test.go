@@ -0,0 +1,123 @@
+package test
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ory/dockertest/docker"
+)
+
+const heighlinerRepository = "ghcr.io/strangelove-ventures/heighliner"
+
+// chainTypes is the registry of heighliner chains that tests can select by name,
+// the version on each entry is the default used when no version is requested
+var chainTypes = map[string]ChainType{
+	"gaia": {
+		Name:         "gaia",
+		Repository:   fmt.Sprintf("%s/gaia", heighlinerRepository),
+		Version:      "v6.0.0-rocks",
+		Bin:          "gaiad",
+		Bech32Prefix: "cosmos",
+		Denom:        "uatom",
+		GasPrices:    "0.01uatom",
+	},
+	"osmosis": {
+		Name:         "osmosis",
+		Repository:   fmt.Sprintf("%s/osmosis", heighlinerRepository),
+		Version:      "v6.0.0",
+		Bin:          "osmosisd",
+		Bech32Prefix: "osmo",
+		Denom:        "uosmo",
+		GasPrices:    "0.0uosmo",
+	},
+	"juno": {
+		Name:         "juno",
+		Repository:   fmt.Sprintf("%s/juno", heighlinerRepository),
+		Version:      "v2.1.0",
+		Bin:          "junod",
+		Bech32Prefix: "juno",
+		Denom:        "ujuno",
+		GasPrices:    "0.0025ujuno",
+	},
+	"akash": {
+		Name:         "akash",
+		Repository:   fmt.Sprintf("%s/akash", heighlinerRepository),
+		Version:      "v0.14.1",
+		Bin:          "akash",
+		Bech32Prefix: "akash",
+		Denom:        "uakt",
+		GasPrices:    "0.025uakt",
+	},
+	"wasmd": {
+		Name:         "wasmd",
+		Repository:   fmt.Sprintf("%s/wasmd", heighlinerRepository),
+		Version:      "v0.21.0",
+		Bin:          "wasmd",
+		Bech32Prefix: "wasm",
+		Denom:        "stake",
+		GasPrices:    "0.0stake",
+	},
+	"simd": {
+		Name:         "simd",
+		Repository:   fmt.Sprintf("%s/ibc-go-simd", heighlinerRepository),
+		Version:      "v2.0.0",
+		Bin:          "simd",
+		Bech32Prefix: "cosmos",
+		Denom:        "stake",
+		GasPrices:    "0.0stake",
+	},
+}
+
+// defaultPorts returns the ports exposed by every chain node container
+func defaultPorts() map[docker.Port]struct{} {
+	return map[docker.Port]struct{}{
+		"26656/tcp": {},
+		"26657/tcp": {},
+		"9090/tcp":  {},
+		"1337/tcp":  {},
+		"1234/tcp":  {},
+	}
+}
+
+// GetChain returns a copy of the registered chain type with the given name at the given version,
+// an empty version selects the registry default
+func GetChain(name, version string) (*ChainType, error) {
+	ct, ok := chainTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown chain type %q, registered chain types: %v", name, ChainTypeNames())
+	}
+	if version != "" {
+		ct.Version = version
+	}
+	if ct.Ports == nil {
+		ct.Ports = defaultPorts()
+	}
+	return &ct, nil
+}
+
+// RegisterChain adds or replaces a chain type in the registry
+func RegisterChain(ct ChainType) {
+	chainTypes[ct.Name] = ct
+}
+
+// ChainTypeNames returns the sorted names of all registered chain types
+func ChainTypeNames() (out []string) {
+	for name := range chainTypes {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return
+}
+
+// Image returns the docker image for the chain type
+func (ct *ChainType) Image() string {
+	return fmt.Sprintf("%s:%s", ct.Repository, ct.Version)
+}
+
+// NodeHome returns the home directory used by the chain binary inside the container
+func (ct *ChainType) NodeHome() string {
+	if ct.HomeDir != "" {
+		return ct.HomeDir
+	}
+	return fmt.Sprintf("/home/.%s", ct.Bin)
+}
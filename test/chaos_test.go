@@ -0,0 +1,278 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// chaosSeedEnv is the environment variable that sets the seed of chaos runs to replay a failed run
+const chaosSeedEnv = "IBC_TEST_CHAOS_SEED"
+
+// ChaosAction is a way of taking a node down
+type ChaosAction string
+
+const (
+	// ChaosStop stops the node container and starts a new one from the same home folder to bring it back
+	ChaosStop ChaosAction = "stop"
+	// ChaosPause freezes the node container and unpauses it to bring it back
+	ChaosPause ChaosAction = "pause"
+)
+
+// ChaosConfig configures a chaos run
+type ChaosConfig struct {
+	// Seed makes the sequence of chaos events reproducible, zero picks a seed from IBC_TEST_CHAOS_SEED
+	// or the current time, the seed is logged either way
+	Seed int64
+	// Interval is the time between two chaos events
+	Interval time.Duration
+	// MaxDownIntervals is the maximum number of intervals a node stays down
+	MaxDownIntervals int
+	// Actions are the ways nodes are taken down, all of them by default
+	Actions []ChaosAction
+	// MaxDownPower is the fraction of the total voting power that may be down at once, zero keeps
+	// it strictly below one third so the chain keeps producing blocks
+	MaxDownPower float64
+}
+
+// ChaosEvent is an entry of the timeline of a chaos run
+type ChaosEvent struct {
+	Elapsed time.Duration
+	Node    string
+	Event   string
+	Err     error
+}
+
+// downNode is a node taken down by a chaos run
+type downNode struct {
+	action  ChaosAction
+	upAfter int
+}
+
+// Chaos randomly takes nodes down and brings them back in the background
+type Chaos struct {
+	cfg        ChaosConfig
+	nodes      TestNodes
+	t          *testing.T
+	rng        *rand.Rand
+	power      map[*TestNode]int64
+	totalPower int64
+	down       map[*TestNode]*downNode
+	start      time.Time
+
+	mu       sync.Mutex
+	timeline []ChaosEvent
+
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// StartChaos starts taking random nodes down on a schedule until the returned chaos is stopped,
+// which happens at the latest when the test finishes
+func (tn TestNodes) StartChaos(ctx context.Context, cfg ChaosConfig) (*Chaos, error) {
+	if len(tn) == 0 {
+		return nil, fmt.Errorf("no nodes to run chaos on")
+	}
+	t := tn[0].t
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+		if env := os.Getenv(chaosSeedEnv); env != "" {
+			seed, err := strconv.ParseInt(env, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", chaosSeedEnv, err)
+			}
+			cfg.Seed = seed
+		}
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = 10 * time.Second
+	}
+	if cfg.MaxDownIntervals == 0 {
+		cfg.MaxDownIntervals = 3
+	}
+	if len(cfg.Actions) == 0 {
+		cfg.Actions = []ChaosAction{ChaosStop, ChaosPause}
+	}
+
+	c := &Chaos{
+		cfg:   cfg,
+		nodes: tn,
+		t:     t,
+		rng:   rand.New(rand.NewSource(cfg.Seed)),
+		power: make(map[*TestNode]int64),
+		down:  make(map[*TestNode]*downNode),
+		done:  make(chan struct{}),
+	}
+	for _, n := range tn {
+		if !n.Validator {
+			continue
+		}
+		stat, err := n.Client.Status(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.power[n] = stat.ValidatorInfo.VotingPower
+		c.totalPower += stat.ValidatorInfo.VotingPower
+	}
+	if c.totalPower == 0 {
+		return nil, fmt.Errorf("no validators with voting power among the nodes to run chaos on")
+	}
+
+	t.Logf("chaos seed %d, replay with %s=%d", cfg.Seed, chaosSeedEnv, cfg.Seed)
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.start = time.Now()
+	go c.run(ctx)
+	t.Cleanup(func() { c.Stop() })
+	return c, nil
+}
+
+// run takes a chaos step every interval until the context is done
+func (c *Chaos) run(ctx context.Context) {
+	defer close(c.done)
+	for tick := 1; ; tick++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.cfg.Interval):
+		}
+		c.step(ctx, tick)
+	}
+}
+
+// step brings back the nodes whose downtime is over and takes one random node down if the voting
+// power limit allows it, the decisions only depend on the seed so a run can be replayed
+func (c *Chaos) step(ctx context.Context, tick int) {
+	for _, n := range c.nodes {
+		if d, ok := c.down[n]; ok && tick >= d.upAfter {
+			c.bringUp(ctx, n, d.action)
+		}
+	}
+
+	n := c.nodes[c.rng.Intn(len(c.nodes))]
+	action := c.cfg.Actions[c.rng.Intn(len(c.cfg.Actions))]
+	downFor := 1 + c.rng.Intn(c.cfg.MaxDownIntervals)
+	if _, ok := c.down[n]; ok {
+		return
+	}
+	if !c.withinPowerLimit(c.downPower() + c.power[n]) {
+		c.record(n, fmt.Sprintf("skip %s, voting power limit", action), nil)
+		return
+	}
+
+	var err error
+	switch action {
+	case ChaosStop:
+		err = n.StopContainer()
+	case ChaosPause:
+		err = n.Pool.Client.PauseContainer(n.Container.ID)
+	}
+	c.record(n, string(action), err)
+	if err == nil {
+		c.down[n] = &downNode{action: action, upAfter: tick + downFor}
+	}
+}
+
+// bringUp brings a node back from the way it was taken down
+func (c *Chaos) bringUp(ctx context.Context, n *TestNode, action ChaosAction) {
+	var err error
+	switch action {
+	case ChaosStop:
+		err = n.RecreateContainer(ctx, n.Chain)
+		c.record(n, "start", err)
+	case ChaosPause:
+		err = n.Pool.Client.UnpauseContainer(n.Container.ID)
+		c.record(n, "unpause", err)
+	}
+	delete(c.down, n)
+}
+
+func (c *Chaos) downPower() (power int64) {
+	for n := range c.down {
+		power += c.power[n]
+	}
+	return power
+}
+
+func (c *Chaos) withinPowerLimit(down int64) bool {
+	if c.cfg.MaxDownPower == 0 {
+		return down*3 < c.totalPower
+	}
+	return float64(down) <= c.cfg.MaxDownPower*float64(c.totalPower)
+}
+
+func (c *Chaos) record(n *TestNode, event string, err error) {
+	e := ChaosEvent{Elapsed: time.Since(c.start).Round(time.Millisecond), Node: n.Name(), Event: event, Err: err}
+	c.mu.Lock()
+	c.timeline = append(c.timeline, e)
+	c.mu.Unlock()
+	if err != nil {
+		c.t.Logf("{%s} chaos %s failed: %v", n.Name(), event, err)
+		return
+	}
+	c.t.Logf("{%s} chaos %s", n.Name(), event)
+}
+
+// Timeline returns the events of the chaos run so far
+func (c *Chaos) Timeline() []ChaosEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ChaosEvent{}, c.timeline...)
+}
+
+// Stop stops the chaos run, brings every node that is down back and logs the seed and the timeline
+func (c *Chaos) Stop() []ChaosEvent {
+	c.stopOnce.Do(func() {
+		c.cancel()
+		<-c.done
+		for _, n := range c.nodes {
+			if d, ok := c.down[n]; ok {
+				c.bringUp(context.Background(), n, d.action)
+			}
+		}
+		c.t.Logf("chaos seed %d timeline:\n%s", c.cfg.Seed, chaosTable(c.Timeline()))
+	})
+	return c.Timeline()
+}
+
+func chaosTable(events []ChaosEvent) string {
+	bldr := new(strings.Builder)
+	w := tabwriter.NewWriter(bldr, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ELAPSED\tNODE\tEVENT\tERROR")
+	for _, e := range events {
+		errStr := ""
+		if e.Err != nil {
+			errStr = e.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Elapsed, e.Node, e.Event, errStr)
+	}
+	_ = w.Flush()
+	return bldr.String()
+}
+
+func TestChainSurvivesChaos(t *testing.T) {
+	gaia, err := GetChain("gaia", "")
+	require.NoError(t, err)
+
+	ctx, _, _, network, chains := SetupTestChains(t, ChainConfig{ChainType: gaia, NumValidators: 4, NumFullNodes: 1})
+	chain := chains[0]
+	StartChains(t, ctx, network, chain)
+
+	chaos, err := chain.Nodes().StartChaos(ctx, ChaosConfig{Interval: 5 * time.Second})
+	require.NoError(t, err)
+	time.Sleep(time.Minute)
+	for _, event := range chaos.Stop() {
+		require.NoError(t, event.Err, "%s %s at %s", event.Node, event.Event, event.Elapsed)
+	}
+
+	require.NoError(t, chain.Nodes().CheckConsensus(ctx, 3, 2*time.Minute))
+}
@@ -0,0 +1,199 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// consensusFailureMarkers are log messages of a node that stopped because it disagrees with the network
+var consensusFailureMarkers = []string{
+	"CONSENSUS FAILURE",
+	"wrong Block.Header.AppHash",
+	"wrong Block.Header.LastResultsHash",
+}
+
+// maxFailureLines is the number of log lines reported for each failed node
+const maxFailureLines = 5
+
+// CheckConsensus waits for every node to commit the given number of new blocks and checks that they all
+// committed the same blocks, nodes that stop short because of a consensus failure or an app hash that
+// diverges from the rest of the network are reported in the error along with their failure logs,
+// a node that can not be queried, such as one that crashed, must reach the given number of blocks
+func (tn TestNodes) CheckConsensus(ctx context.Context, blocks int64, timeout time.Duration) error {
+	start := make([]int64, len(tn))
+	for i, n := range tn {
+		if height, err := n.Height(ctx); err == nil {
+			start[i] = height
+		}
+	}
+
+	heights := make([]int64, len(tn))
+	deadline := time.Now().Add(timeout)
+	for {
+		done := true
+		for i, n := range tn {
+			if height, err := n.Height(ctx); err == nil {
+				heights[i] = height
+			}
+			if heights[i] < start[i]+blocks {
+				done = false
+			}
+		}
+		if done || time.Now().After(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+
+	var healthy, stalled TestNodes
+	var stalledHeights []int64
+	for i, n := range tn {
+		if heights[i] >= start[i]+blocks {
+			healthy = append(healthy, n)
+		} else {
+			stalled = append(stalled, n)
+			stalledHeights = append(stalledHeights, heights[i])
+		}
+	}
+
+	var problems []string
+	if fork := healthy.compareBlocks(ctx, heights); fork != "" {
+		problems = append(problems, fork)
+	}
+	if len(healthy) == 0 {
+		problems = append(problems, fmt.Sprintf("chain halted, no node committed %d blocks within %s", blocks, timeout))
+	}
+	for i, n := range stalled {
+		problem := fmt.Sprintf("%s stalled at height %d", n.Name(), stalledHeights[i])
+		if len(healthy) > 0 {
+			if divergence := n.compareAppHash(ctx, healthy[0]); divergence != "" {
+				problem += ": " + divergence
+			}
+		}
+		if lines := n.consensusFailureLogs(); len(lines) > 0 {
+			problem += "\n\t" + strings.Join(lines, "\n\t")
+		}
+		problems = append(problems, problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("consensus failure:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// compareBlocks checks that the nodes committed the same block at the highest height they all reached
+func (tn TestNodes) compareBlocks(ctx context.Context, heights []int64) string {
+	if len(tn) < 2 {
+		return ""
+	}
+	var common int64
+	for i, n := range tn {
+		height, err := n.Height(ctx)
+		if err != nil {
+			height = heights[i]
+		}
+		if common == 0 || height < common {
+			common = height
+		}
+	}
+
+	var reference []byte
+	for _, n := range tn {
+		block, err := n.Client.Block(ctx, &common)
+		if err != nil {
+			return fmt.Sprintf("failed to query block %d on %s: %v", common, n.Name(), err)
+		}
+		if reference == nil {
+			reference = block.BlockID.Hash
+			continue
+		}
+		if !bytes.Equal(reference, block.BlockID.Hash) {
+			return fmt.Sprintf("nodes committed different blocks at height %d: %s has %X, %s has %X",
+				common, tn[0].Name(), reference, n.Name(), block.BlockID.Hash)
+		}
+	}
+	return ""
+}
+
+// compareAppHash compares the app hash of the last block the node committed with the app hash the rest of the
+// network, represented by a healthy node, agreed on in the header of the next block
+func (tn *TestNode) compareAppHash(ctx context.Context, healthy *TestNode) string {
+	info, err := tn.Client.ABCIInfo(ctx)
+	if err != nil {
+		return fmt.Sprintf("app is not responding: %v", err)
+	}
+	next := info.Response.LastBlockHeight + 1
+	block, err := healthy.Client.Block(ctx, &next)
+	if err != nil {
+		return ""
+	}
+	if !bytes.Equal(info.Response.LastBlockAppHash, block.Block.AppHash) {
+		return fmt.Sprintf("app hash diverged after height %d, node has %X, network has %X",
+			info.Response.LastBlockHeight, info.Response.LastBlockAppHash, block.Block.AppHash)
+	}
+	return ""
+}
+
+// consensusFailureLogs returns the last lines of the node's log file that indicate a consensus failure
+func (tn *TestNode) consensusFailureLogs() []string {
+	f, err := os.Open(tn.LogFilePath())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		for _, marker := range consensusFailureMarkers {
+			if strings.Contains(scanner.Text(), marker) {
+				lines = append(lines, scanner.Text())
+				break
+			}
+		}
+	}
+	if len(lines) > maxFailureLines {
+		lines = lines[len(lines)-maxFailureLines:]
+	}
+	return lines
+}
+
+func TestMixedVersionValidators(t *testing.T) {
+	gaiaV6, err := GetChain("gaia", "v6.0.0-rocks")
+	require.NoError(t, err)
+	gaiaV7, err := GetChain("gaia", "v7.0.0")
+	require.NoError(t, err)
+
+	ctx, _, _, network, chains := SetupTestChains(t, ChainConfig{
+		ChainType:      gaiaV6,
+		NumValidators:  4,
+		NodeChainTypes: map[int]*ChainType{3: gaiaV7},
+	})
+	StartChains(t, ctx, network, chains...)
+
+	// v7 is consensus breaking, the v6 validators hold more than 2/3 of the voting power and keep the chain
+	// going while the v7 validator diverges from them
+	err = chains[0].Nodes().CheckConsensus(ctx, 5, 2*time.Minute)
+	require.Error(t, err)
+	t.Log(err)
+
+	v := chains[0].Validators
+	require.Contains(t, err.Error(), fmt.Sprintf("%s stalled", v[3].Name()))
+	for _, n := range v[:3] {
+		require.NotContains(t, err.Error(), fmt.Sprintf("%s stalled", n.Name()))
+	}
+	require.Regexp(t, "app hash diverged|CONSENSUS FAILURE|wrong Block.Header.AppHash", err.Error())
+}
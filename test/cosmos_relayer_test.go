@@ -0,0 +1,121 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ory/dockertest"
+)
+
+const (
+	rlyRepository = "ghcr.io/cosmos/relayer"
+	rlyVersion    = "v1.0.0"
+	rlyKey        = "relayer"
+	rlyHome       = "/home/relayer"
+)
+
+// CosmosRelayer is the cosmos/relayer (rly) implementation of Relayer
+type CosmosRelayer struct {
+	*DockerRelayer
+}
+
+var _ Relayer = (*CosmosRelayer)(nil)
+
+// rlyChainConfig is the chain configuration file consumed by `rly chains add`
+type rlyChainConfig struct {
+	Key            string  `json:"key"`
+	ChainID        string  `json:"chain-id"`
+	RPCAddr        string  `json:"rpc-addr"`
+	AccountPrefix  string  `json:"account-prefix"`
+	GasAdjustment  float64 `json:"gas-adjustment"`
+	GasPrices      string  `json:"gas-prices"`
+	TrustingPeriod string  `json:"trusting-period"`
+}
+
+// NewCosmosRelayer creates a cosmos/relayer whose containers are attached to the given network
+func NewCosmosRelayer(t *testing.T, home string, pool *dockertest.Pool, networkID string) *CosmosRelayer {
+	return &CosmosRelayer{NewDockerRelayer(t, "rly", home, rlyHome, rlyRepository, rlyVersion, pool, networkID)}
+}
+
+// InitConfig initializes the relayer configuration in the relayer home
+func (r *CosmosRelayer) InitConfig(ctx context.Context) error {
+	command := []string{"rly", "config", "init", "--home", rlyHome}
+	return handleNodeJobError(r.RelayerJob(ctx, command))
+}
+
+// AddChainConfiguration implements Relayer
+func (r *CosmosRelayer) AddChainConfiguration(ctx context.Context, chain *TestChain) error {
+	if _, err := os.Stat(path.Join(r.Dir(), "config", "config.yaml")); os.IsNotExist(err) {
+		if err := r.InitConfig(ctx); err != nil {
+			return err
+		}
+	}
+
+	chainConfig := rlyChainConfig{
+		Key:            rlyKey,
+		ChainID:        chain.ChainID,
+		RPCAddr:        fmt.Sprintf("http://%s:26657", chain.RelayerNode().Name()),
+		AccountPrefix:  chain.Chain.Bech32Prefix,
+		GasAdjustment:  1.3,
+		GasPrices:      chain.Chain.GasPrices,
+		TrustingPeriod: "330h",
+	}
+	bz, err := json.Marshal(chainConfig)
+	if err != nil {
+		return err
+	}
+
+	fileName := fmt.Sprintf("%s.json", chain.ChainID)
+	if err := ioutil.WriteFile(path.Join(r.Dir(), fileName), bz, 0644); err != nil { //nolint
+		return err
+	}
+
+	command := []string{"rly", "chains", "add", "-f", path.Join(rlyHome, fileName), "--home", rlyHome}
+	return handleNodeJobError(r.RelayerJob(ctx, command))
+}
+
+// RestoreKey implements Relayer
+func (r *CosmosRelayer) RestoreKey(ctx context.Context, chainID, mnemonic string) error {
+	command := []string{"rly", "keys", "restore", chainID, rlyKey, mnemonic, "--home", rlyHome}
+	return handleNodeJobError(r.RelayerJob(ctx, command))
+}
+
+// GeneratePath implements Relayer
+func (r *CosmosRelayer) GeneratePath(ctx context.Context, srcChainID, dstChainID, pathName string) error {
+	command := []string{"rly", "paths", "generate", srcChainID, dstChainID, pathName,
+		"--port", "transfer",
+		"--home", rlyHome,
+	}
+	return handleNodeJobError(r.RelayerJob(ctx, command))
+}
+
+// LinkPath implements Relayer
+func (r *CosmosRelayer) LinkPath(ctx context.Context, pathName string) error {
+	command := []string{"rly", "tx", "link", pathName, "--home", rlyHome}
+	return handleNodeJobError(r.RelayerJob(ctx, command))
+}
+
+// StartRelayer implements Relayer
+func (r *CosmosRelayer) StartRelayer(ctx context.Context, pathName string) error {
+	return r.StartRelayerContainer([]string{"rly", "start", pathName, "--home", rlyHome})
+}
+
+// StopRelayer implements Relayer
+func (r *CosmosRelayer) StopRelayer(ctx context.Context) error {
+	return r.StopRelayerContainer(ctx)
+}
+
+// FlushPackets implements Relayer, rly relays everything pending on the path so the channel is not needed
+func (r *CosmosRelayer) FlushPackets(ctx context.Context, pathName, channelID string) error {
+	command := []string{"rly", "tx", "relay-packets", pathName, "--home", rlyHome}
+	if err := handleNodeJobError(r.RelayerJob(ctx, command)); err != nil {
+		return err
+	}
+	command = []string{"rly", "tx", "relay-acknowledgements", pathName, "--home", rlyHome}
+	return handleNodeJobError(r.RelayerJob(ctx, command))
+}
@@ -0,0 +1,135 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+// CosmovisorPath returns the path of the cosmovisor binary inside the node container
+func (tn *TestNode) CosmovisorPath() string {
+	return path.Join(tn.NodeHome(), "cosmovisor", "cosmovisor")
+}
+
+// startEnv returns the environment of the node container, which configures cosmovisor in cosmovisor mode
+func (tn *TestNode) startEnv() []string {
+	if tn.Cosmovisor == nil {
+		return nil
+	}
+	return []string{
+		fmt.Sprintf("DAEMON_NAME=%s", tn.Chain.Bin),
+		fmt.Sprintf("DAEMON_HOME=%s", tn.NodeHome()),
+		"DAEMON_RESTART_AFTER_UPGRADE=true",
+		"DAEMON_ALLOW_DOWNLOAD_BINARIES=false",
+		"UNSAFE_SKIP_BACKUP=true",
+	}
+}
+
+// SetupCosmovisor copies the cosmovisor binary into the node's home folder and stages the binary
+// of the node's chain type as the cosmovisor genesis binary
+func (tn *TestNode) SetupCosmovisor(ctx context.Context) error {
+	bz, err := ioutil.ReadFile(tn.Cosmovisor.Binary)
+	if err != nil {
+		return fmt.Errorf("failed to read cosmovisor binary: %w", err)
+	}
+	dir := path.Join(tn.Dir(), "cosmovisor")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path.Join(dir, "cosmovisor"), bz, 0755); err != nil { //nolint
+		return err
+	}
+	return tn.stageBinary(ctx, tn.Chain, path.Join(tn.NodeHome(), "cosmovisor", "genesis", "bin"))
+}
+
+// StageUpgradeBinary copies the binary of a chain type into the cosmovisor folder of the named upgrade,
+// cosmovisor switches to it when the chain halts for that upgrade, the binary runs in the image of the
+// node's current chain type, so it is checked to start there as it may be linked differently
+func (tn *TestNode) StageUpgradeBinary(ctx context.Context, upgradeName string, chainType *ChainType) error {
+	dir := path.Join(tn.NodeHome(), "cosmovisor", "upgrades", upgradeName, "bin")
+	if err := tn.stageBinary(ctx, chainType, dir); err != nil {
+		return err
+	}
+	command := []string{path.Join(dir, chainType.Bin), "version"}
+	if err := handleNodeJobError(tn.NodeJob(ctx, command)); err != nil {
+		return fmt.Errorf("%s binary of %s does not run in %s: %w", chainType.Bin, chainType.Image(), tn.Chain.Image(), err)
+	}
+	return nil
+}
+
+// stageBinary copies the chain binary out of a chain type's image into a directory of the node's home folder
+func (tn *TestNode) stageBinary(ctx context.Context, chainType *ChainType, dir string) error {
+	command := []string{"sh", "-c", fmt.Sprintf(`mkdir -p %s && cp "$(command -v %s)" %s/`, dir, chainType.Bin, dir)}
+	return handleNodeJobError(tn.ImageJob(ctx, chainType.Image(), command))
+}
+
+// StageUpgrade stages the binary of a new version of the chain type for the named upgrade on every node
+func (tc *TestChain) StageUpgrade(ctx context.Context, upgradeName, version string) error {
+	chainType := *tc.Chain
+	chainType.Version = version
+
+	var eg errgroup.Group
+	for _, n := range tc.Nodes() {
+		n := n
+		eg.Go(func() error { return n.StageUpgradeBinary(ctx, upgradeName, &chainType) })
+	}
+	return eg.Wait()
+}
+
+// UpgradeWithCosmovisor runs a software upgrade of a chain whose nodes run under cosmovisor, the binary of
+// the new version is staged on every node before the upgrade is proposed and the chain is expected
+// to continue past the upgrade height on the new binary without the containers being touched
+func (tc *TestChain) UpgradeWithCosmovisor(t *testing.T, ctx context.Context, upgradeName, version string, heightDelta int64) {
+	for _, n := range tc.Nodes() {
+		require.NotNil(t, n.Cosmovisor, "%s is not running under cosmovisor", n.Name())
+	}
+	require.NoError(t, tc.StageUpgrade(ctx, upgradeName, version))
+
+	plan := tc.scheduleUpgrade(t, ctx, upgradeName, version, heightDelta)
+	tc.Nodes().WaitForHeight(plan.Height + 2)
+
+	for _, n := range tc.Nodes() {
+		current, err := n.CosmovisorCurrent()
+		require.NoError(t, err)
+		require.Equal(t, path.Join("upgrades", upgradeName), current,
+			"%s is not running the binary of upgrade %s", n.Name(), upgradeName)
+	}
+}
+
+// CosmovisorCurrent returns the folder the cosmovisor current symlink of the node points to, relative to
+// the cosmovisor folder, such as genesis or upgrades/<name>
+func (tn *TestNode) CosmovisorCurrent() (string, error) {
+	link, err := os.Readlink(path.Join(tn.Dir(), "cosmovisor", "current"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read cosmovisor current symlink of %s: %w", tn.Name(), err)
+	}
+	// cosmovisor links to an absolute path inside the container
+	return strings.TrimPrefix(path.Clean(link), path.Join(tn.NodeHome(), "cosmovisor")+"/"), nil
+}
+
+func TestCosmovisorUpgrade(t *testing.T) {
+	cosmovisor := os.Getenv("COSMOVISOR_BIN")
+	if cosmovisor == "" {
+		t.Skip("COSMOVISOR_BIN is not set to a cosmovisor binary")
+	}
+	gaia, err := GetChain("gaia", "v6.0.0-rocks")
+	require.NoError(t, err)
+
+	ctx, _, _, network, chains := SetupTestChains(t, ChainConfig{
+		ChainType:     gaia,
+		NumValidators: 2,
+		VotingPeriod:  15 * time.Second,
+		Cosmovisor:    &CosmovisorConfig{Binary: cosmovisor},
+	})
+	StartChains(t, ctx, network, chains...)
+
+	chains[0].UpgradeWithCosmovisor(t, ctx, "v7-Theta", "v7.0.0", 30)
+}
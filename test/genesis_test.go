@@ -0,0 +1,320 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stretchr/testify/require"
+)
+
+// genesisCodec encodes and decodes the module genesis states modified by the typed genesis hooks
+var genesisCodec = simapp.MakeTestEncodingConfig().Marshaler
+
+// ModifyGenesis runs the hooks in order on the node's genesis file
+func (tn *TestNode) ModifyGenesis(hooks ...GenesisHook) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+	genesis, err := ioutil.ReadFile(tn.GenesisFilePath())
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		if genesis, err = hook(genesis); err != nil {
+			return fmt.Errorf("genesis hook failed on %s: %w", tn.Name(), err)
+		}
+	}
+	return ioutil.WriteFile(tn.GenesisFilePath(), genesis, 0644) //nolint
+}
+
+// SetGenesisValue returns a hook that sets the value at a dot separated json path of the genesis file,
+// such as app_state.gov.voting_params.voting_period, numeric path elements index into arrays
+func SetGenesisValue(path string, value interface{}) GenesisHook {
+	return genesisValueHook(path, value, false)
+}
+
+// genesisValueHook sets the value at a json path of the genesis file, leaving the genesis
+// untouched if the path does not exist and skipMissing is set
+func genesisValueHook(path string, value interface{}, skipMissing bool) GenesisHook {
+	return func(genesis []byte) ([]byte, error) {
+		var doc interface{}
+		dec := json.NewDecoder(bytes.NewReader(genesis))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		keys := strings.Split(path, ".")
+		parent := doc
+		for i, key := range keys {
+			last := i == len(keys)-1
+			switch node := parent.(type) {
+			case map[string]interface{}:
+				if last {
+					node[key] = value
+					break
+				}
+				child, ok := node[key]
+				if !ok && skipMissing {
+					return genesis, nil
+				}
+				if !ok {
+					return nil, fmt.Errorf("genesis path %s not found at %s", path, key)
+				}
+				parent = child
+			case []interface{}:
+				idx, err := strconv.Atoi(key)
+				if err != nil || idx < 0 || idx >= len(node) {
+					return nil, fmt.Errorf("genesis path %s has invalid index %s", path, key)
+				}
+				if last {
+					node[idx] = value
+					break
+				}
+				parent = node[idx]
+			default:
+				return nil, fmt.Errorf("genesis path %s traverses a value at %s", path, key)
+			}
+		}
+		return json.MarshalIndent(doc, "", "  ")
+	}
+}
+
+// mutateModuleGenesis decodes the genesis state of a module into state, runs mutate and encodes the state back
+func mutateModuleGenesis(genesis []byte, module string, state codec.ProtoMarshaler, mutate func() error) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(genesis, &doc); err != nil {
+		return nil, err
+	}
+	var appState map[string]json.RawMessage
+	if err := json.Unmarshal(doc["app_state"], &appState); err != nil {
+		return nil, err
+	}
+	moduleState, ok := appState[module]
+	if !ok {
+		return nil, fmt.Errorf("genesis has no %s module state", module)
+	}
+	if err := genesisCodec.UnmarshalJSON(moduleState, state); err != nil {
+		return nil, fmt.Errorf("failed to decode %s genesis: %w", module, err)
+	}
+
+	if err := mutate(); err != nil {
+		return nil, err
+	}
+
+	bz, err := genesisCodec.MarshalJSON(state)
+	if err != nil {
+		return nil, err
+	}
+	appState[module] = bz
+	if doc["app_state"], err = json.Marshal(appState); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// AuthGenesisHook returns a hook that modifies the auth module genesis state
+func AuthGenesisHook(mutate func(*authtypes.GenesisState)) GenesisHook {
+	return func(genesis []byte) ([]byte, error) {
+		var state authtypes.GenesisState
+		return mutateModuleGenesis(genesis, authtypes.ModuleName, &state, func() error {
+			mutate(&state)
+			return nil
+		})
+	}
+}
+
+// BankGenesisHook returns a hook that modifies the bank module genesis state
+func BankGenesisHook(mutate func(*banktypes.GenesisState)) GenesisHook {
+	return func(genesis []byte) ([]byte, error) {
+		var state banktypes.GenesisState
+		return mutateModuleGenesis(genesis, banktypes.ModuleName, &state, func() error {
+			mutate(&state)
+			return nil
+		})
+	}
+}
+
+// StakingGenesisHook returns a hook that modifies the staking module genesis state
+func StakingGenesisHook(mutate func(*stakingtypes.GenesisState)) GenesisHook {
+	return func(genesis []byte) ([]byte, error) {
+		var state stakingtypes.GenesisState
+		return mutateModuleGenesis(genesis, stakingtypes.ModuleName, &state, func() error {
+			mutate(&state)
+			return nil
+		})
+	}
+}
+
+// GovGenesisHook returns a hook that modifies the gov module genesis state
+func GovGenesisHook(mutate func(*govtypes.GenesisState)) GenesisHook {
+	return func(genesis []byte) ([]byte, error) {
+		var state govtypes.GenesisState
+		return mutateModuleGenesis(genesis, govtypes.ModuleName, &state, func() error {
+			mutate(&state)
+			return nil
+		})
+	}
+}
+
+// SlashingGenesisHook returns a hook that modifies the slashing module genesis state
+func SlashingGenesisHook(mutate func(*slashingtypes.GenesisState)) GenesisHook {
+	return func(genesis []byte) ([]byte, error) {
+		var state slashingtypes.GenesisState
+		return mutateModuleGenesis(genesis, slashingtypes.ModuleName, &state, func() error {
+			mutate(&state)
+			return nil
+		})
+	}
+}
+
+// SetGovVotingPeriod returns a hook that shortens the gov deposit and voting periods
+func SetGovVotingPeriod(period time.Duration) GenesisHook {
+	return GovGenesisHook(func(state *govtypes.GenesisState) {
+		state.DepositParams.MaxDepositPeriod = period
+		state.VotingParams.VotingPeriod = period
+	})
+}
+
+// SetSlashingWindow returns a hook that sets the slashing signed blocks window
+func SetSlashingWindow(signedBlocksWindow int64) GenesisHook {
+	return SlashingGenesisHook(func(state *slashingtypes.GenesisState) {
+		state.Params.SignedBlocksWindow = signedBlocksWindow
+	})
+}
+
+// SetAllowedClients returns a hook that sets the light client types the ibc module allows to be created
+func SetAllowedClients(clientTypes ...string) GenesisHook {
+	allowed := make([]interface{}, len(clientTypes))
+	for i, ct := range clientTypes {
+		allowed[i] = ct
+	}
+	return SetGenesisValue("app_state.ibc.client_genesis.params.allowed_clients", allowed)
+}
+
+// AddModuleAccount returns a hook that adds an empty module account with the given permissions,
+// the address is encoded with the chain's bech32 prefix
+func AddModuleAccount(bech32Prefix, name string, permissions ...string) GenesisHook {
+	return func(genesis []byte) ([]byte, error) {
+		address, err := sdk.Bech32ifyAddressBytes(bech32Prefix, authtypes.NewModuleAddress(name))
+		if err != nil {
+			return nil, err
+		}
+		var state authtypes.GenesisState
+		return mutateModuleGenesis(genesis, authtypes.ModuleName, &state, func() error {
+			accounts, err := authtypes.UnpackAccounts(state.Accounts)
+			if err != nil {
+				return err
+			}
+			account := authtypes.NewEmptyModuleAccount(name, permissions...)
+			account.Address = address
+			if err := account.SetAccountNumber(uint64(len(accounts))); err != nil {
+				return err
+			}
+			state.Accounts, err = authtypes.PackAccounts(append(accounts, account))
+			return err
+		})
+	}
+}
+
+// FundGenesisAccount returns a hook that adds an account with the given balance, the address
+// is used as is so it must have the chain's bech32 prefix
+func FundGenesisAccount(address string, coins sdk.Coins) GenesisHook {
+	return func(genesis []byte) ([]byte, error) {
+		var authState authtypes.GenesisState
+		genesis, err := mutateModuleGenesis(genesis, authtypes.ModuleName, &authState, func() error {
+			accounts, err := authtypes.UnpackAccounts(authState.Accounts)
+			if err != nil {
+				return err
+			}
+			account := &authtypes.BaseAccount{Address: address}
+			authState.Accounts, err = authtypes.PackAccounts(append(accounts, account))
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return BankGenesisHook(func(state *banktypes.GenesisState) {
+			state.Balances = append(state.Balances, banktypes.Balance{Address: address, Coins: coins})
+			state.Supply = state.Supply.Add(coins...)
+		})(genesis)
+	}
+}
+
+// SetBondDenom returns a hook that replaces the stake denom of a freshly initialized genesis
+// with the given bond denom in the staking, gov, mint and crisis modules
+func SetBondDenom(denom string) GenesisHook {
+	hooks := []GenesisHook{
+		StakingGenesisHook(func(state *stakingtypes.GenesisState) {
+			state.Params.BondDenom = denom
+		}),
+		GovGenesisHook(func(state *govtypes.GenesisState) {
+			for i := range state.DepositParams.MinDeposit {
+				state.DepositParams.MinDeposit[i].Denom = denom
+			}
+		}),
+		genesisValueHook("app_state.mint.params.mint_denom", denom, true),
+		genesisValueHook("app_state.crisis.constant_fee.denom", denom, true),
+	}
+	return func(genesis []byte) (out []byte, err error) {
+		out = genesis
+		for _, hook := range hooks {
+			if out, err = hook(out); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+}
+
+func TestSetGenesisValue(t *testing.T) {
+	genesis := []byte(`{"app_state":{"gov":{"voting_params":{"voting_period":"172800s"}},"list":[{"a":1},{"a":2}]}}`)
+
+	out, err := SetGenesisValue("app_state.gov.voting_params.voting_period", "10s")(genesis)
+	require.NoError(t, err)
+	out, err = SetGenesisValue("app_state.list.1.a", 3)(out)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"app_state":{"gov":{"voting_params":{"voting_period":"10s"}},"list":[{"a":1},{"a":3}]}}`, string(out))
+
+	_, err = SetGenesisValue("app_state.staking.params.bond_denom", "uatom")(genesis)
+	require.Error(t, err)
+}
+
+func TestAddModuleAccount(t *testing.T) {
+	genesis := []byte(`{"app_state":{"auth":{"params":{"max_memo_characters":"256"},"accounts":[
+		{"@type":"/cosmos.auth.v1beta1.BaseAccount","address":"osmo1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqzlf5ua","account_number":"0"}]}}}`)
+
+	out, err := AddModuleAccount("osmo", "mint", authtypes.Minter)(genesis)
+	require.NoError(t, err)
+
+	var state authtypes.GenesisState
+	_, err = mutateModuleGenesis(out, authtypes.ModuleName, &state, func() error { return nil })
+	require.NoError(t, err)
+	accounts, err := authtypes.UnpackAccounts(state.Accounts)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+
+	account, ok := accounts[1].(authtypes.ModuleAccountI)
+	require.True(t, ok)
+	require.Equal(t, "mint", account.GetName())
+	require.Equal(t, []string{authtypes.Minter}, account.GetPermissions())
+	require.Equal(t, uint64(1), account.GetAccountNumber())
+	expected, err := sdk.Bech32ifyAddressBytes("osmo", authtypes.NewModuleAddress("mint"))
+	require.NoError(t, err)
+	require.Equal(t, expected, account.(*authtypes.ModuleAccount).Address)
+	require.Equal(t, "osmo1", expected[:5])
+}
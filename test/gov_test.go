@@ -0,0 +1,220 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/avast/retry-go"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	paramsproposal "github.com/cosmos/cosmos-sdk/x/params/types/proposal"
+	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+	"github.com/stretchr/testify/require"
+)
+
+// SubmitProposal submits a governance proposal with an initial deposit from a key in the node's keyring
+// and returns the id of the new proposal
+func (tn *TestNode) SubmitProposal(ctx context.Context, keyName string, content govtypes.Content,
+	deposit sdk.Coins) (uint64, error) {
+	proposer, err := tn.AccountAddress(keyName)
+	if err != nil {
+		return 0, err
+	}
+	msg := &govtypes.MsgSubmitProposal{InitialDeposit: deposit, Proposer: proposer}
+	if err := msg.SetContent(content); err != nil {
+		return 0, err
+	}
+	res, err := tn.SendMsgs(ctx, keyName, msg)
+	if err != nil {
+		return 0, err
+	}
+	return proposalIDFromLogs(res.Logs)
+}
+
+// SubmitTextProposal submits a text proposal and returns its id
+func (tn *TestNode) SubmitTextProposal(ctx context.Context, keyName, title, description string,
+	deposit sdk.Coins) (uint64, error) {
+	return tn.SubmitProposal(ctx, keyName, govtypes.NewTextProposal(title, description), deposit)
+}
+
+// SubmitParamChangeProposal submits a parameter change proposal and returns its id
+func (tn *TestNode) SubmitParamChangeProposal(ctx context.Context, keyName, title, description string,
+	changes []paramsproposal.ParamChange, deposit sdk.Coins) (uint64, error) {
+	content := paramsproposal.NewParameterChangeProposal(title, description, changes)
+	return tn.SubmitProposal(ctx, keyName, content, deposit)
+}
+
+// SubmitSoftwareUpgradeProposal submits a software upgrade proposal for the plan and returns its id
+func (tn *TestNode) SubmitSoftwareUpgradeProposal(ctx context.Context, keyName, title, description string,
+	plan upgradetypes.Plan, deposit sdk.Coins) (uint64, error) {
+	content := &upgradetypes.SoftwareUpgradeProposal{Title: title, Description: description, Plan: plan}
+	return tn.SubmitProposal(ctx, keyName, content, deposit)
+}
+
+// SubmitClientUpdateProposal submits a proposal to replace the state of an expired or frozen IBC client
+// with the state of a substitute client and returns its id, the proposal type belongs to ibc-go
+// so it is submitted with the chain binary
+func (tn *TestNode) SubmitClientUpdateProposal(ctx context.Context, keyName, title, description,
+	subjectClientID, substituteClientID string, deposit sdk.Coins) (uint64, error) {
+	command := tn.TxCommand(keyName, "gov", "submit-proposal", "update-client", subjectClientID, substituteClientID,
+		"--title", title,
+		"--description", description,
+		"--deposit", deposit.String(),
+		"--output", "json",
+	)
+	res, err := tn.NodeJob(ctx, command)
+	if err := handleNodeJobError(res, err); err != nil {
+		return 0, err
+	}
+	var txRes struct {
+		Code   uint32              `json:"code"`
+		RawLog string              `json:"raw_log"`
+		Logs   sdk.ABCIMessageLogs `json:"logs"`
+	}
+	if err := json.Unmarshal([]byte(res.Stdout), &txRes); err != nil {
+		return 0, fmt.Errorf("failed to decode tx response: %w", err)
+	}
+	if txRes.Code != 0 {
+		return 0, fmt.Errorf("client update proposal failed with code %d: %s", txRes.Code, txRes.RawLog)
+	}
+	return proposalIDFromLogs(txRes.Logs)
+}
+
+// MinDeposit returns the minimum deposit for a proposal to enter the voting period
+func (tn *TestNode) MinDeposit(ctx context.Context) (sdk.Coins, error) {
+	res, err := govtypes.NewQueryClient(tn.CliContext()).Params(ctx, &govtypes.QueryParamsRequest{
+		ParamsType: govtypes.ParamDeposit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.DepositParams.MinDeposit, nil
+}
+
+// proposalIDFromLogs returns the id of the proposal created by a submit proposal transaction
+func proposalIDFromLogs(logs sdk.ABCIMessageLogs) (uint64, error) {
+	for _, log := range logs {
+		for _, event := range log.Events {
+			if event.Type != govtypes.EventTypeSubmitProposal {
+				continue
+			}
+			for _, attr := range event.Attributes {
+				if attr.Key == govtypes.AttributeKeyProposalID {
+					return strconv.ParseUint(attr.Value, 10, 64)
+				}
+			}
+		}
+	}
+	return 0, fmt.Errorf("no proposal id in tx logs")
+}
+
+// Deposit adds a deposit to a proposal from a key in the node's keyring
+func (tn *TestNode) Deposit(ctx context.Context, keyName string, proposalID uint64, amount sdk.Coins) error {
+	depositor, err := tn.AccountAddress(keyName)
+	if err != nil {
+		return err
+	}
+	_, err = tn.SendMsgs(ctx, keyName, &govtypes.MsgDeposit{ProposalId: proposalID, Depositor: depositor, Amount: amount})
+	return err
+}
+
+// Vote votes on a proposal with a key in the node's keyring
+func (tn *TestNode) Vote(ctx context.Context, keyName string, proposalID uint64, option govtypes.VoteOption) error {
+	voter, err := tn.AccountAddress(keyName)
+	if err != nil {
+		return err
+	}
+	_, err = tn.SendMsgs(ctx, keyName, &govtypes.MsgVote{ProposalId: proposalID, Voter: voter, Option: option})
+	return err
+}
+
+// VoteOnProposal has every validator in the set vote on a proposal with its validator key
+func (tn TestNodes) VoteOnProposal(ctx context.Context, proposalID uint64, option govtypes.VoteOption) error {
+	voted := 0
+	for _, n := range tn {
+		if !n.Validator {
+			continue
+		}
+		if err := n.Vote(ctx, valKey, proposalID, option); err != nil {
+			return fmt.Errorf("%s failed to vote on proposal %d: %w", n.Name(), proposalID, err)
+		}
+		voted++
+	}
+	if voted == 0 {
+		return fmt.Errorf("no validators among the nodes to vote on proposal %d", proposalID)
+	}
+	return nil
+}
+
+// ProposalStatus returns the status of a proposal, it is queried with the chain binary
+// so proposals with content types unknown to the framework can be queried
+func (tn *TestNode) ProposalStatus(ctx context.Context, proposalID uint64) (govtypes.ProposalStatus, error) {
+	var proposal struct {
+		Status string `json:"status"`
+	}
+	if err := tn.ExecJSON(ctx, tn.QueryCommand("gov", "proposal", strconv.FormatUint(proposalID, 10)), &proposal); err != nil {
+		return govtypes.StatusNil, err
+	}
+	status, ok := govtypes.ProposalStatus_value[proposal.Status]
+	if !ok {
+		return govtypes.StatusNil, fmt.Errorf("unknown proposal status %q", proposal.Status)
+	}
+	return govtypes.ProposalStatus(status), nil
+}
+
+// WaitForProposalStatus waits until the voting period of a proposal has ended and returns its final status,
+// which is passed, rejected or failed
+func (tn *TestNode) WaitForProposalStatus(ctx context.Context, proposalID uint64, timeout time.Duration) (govtypes.ProposalStatus, error) {
+	var status govtypes.ProposalStatus
+	err := retry.Do(func() (err error) {
+		status, err = tn.ProposalStatus(ctx, proposalID)
+		if err != nil {
+			return err
+		}
+		switch status {
+		case govtypes.StatusPassed, govtypes.StatusRejected, govtypes.StatusFailed:
+			return nil
+		}
+		return fmt.Errorf("proposal %d on %s is still %s", proposalID, tn.ChainID, status)
+	}, retry.Context(ctx), retry.Delay(time.Second), retry.DelayType(retry.FixedDelay),
+		retry.Attempts(uint(timeout/time.Second)+1), retry.LastErrorOnly(true))
+	return status, err
+}
+
+// PassProposal has every validator vote yes on a proposal and waits for it to pass
+func (tn TestNodes) PassProposal(ctx context.Context, proposalID uint64, timeout time.Duration) error {
+	if err := tn.VoteOnProposal(ctx, proposalID, govtypes.OptionYes); err != nil {
+		return err
+	}
+	status, err := tn[0].WaitForProposalStatus(ctx, proposalID, timeout)
+	if err != nil {
+		return err
+	}
+	if status != govtypes.StatusPassed {
+		return fmt.Errorf("proposal %d ended with status %s", proposalID, status)
+	}
+	return nil
+}
+
+func TestGovernanceProposal(t *testing.T) {
+	gaia, err := GetChain("gaia", "")
+	require.NoError(t, err)
+
+	ctx, _, _, network, chains := SetupTestChains(t,
+		ChainConfig{ChainType: gaia, NumValidators: 2, VotingPeriod: 15 * time.Second},
+	)
+	chain := chains[0]
+	StartChains(t, ctx, network, chain)
+
+	proposer := chain.Validators[0]
+	deposit, err := proposer.MinDeposit(ctx)
+	require.NoError(t, err)
+
+	proposalID, err := proposer.SubmitTextProposal(ctx, valKey, "test", "test proposal", deposit)
+	require.NoError(t, err)
+	require.NoError(t, chain.Validators.PassProposal(ctx, proposalID, time.Minute))
+}
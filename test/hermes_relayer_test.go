@@ -0,0 +1,234 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ory/dockertest"
+	"github.com/pelletier/go-toml"
+)
+
+const (
+	hermesRepository = "informalsystems/hermes"
+	hermesVersion    = "0.9.0"
+	hermesKey        = "relayer"
+	hermesHome       = "/home/hermes"
+)
+
+// HermesRelayer is the informalsystems/hermes implementation of Relayer
+type HermesRelayer struct {
+	*DockerRelayer
+	chains map[string]*TestChain
+	paths  map[string]hermesPath
+}
+
+var _ Relayer = (*HermesRelayer)(nil)
+
+// hermesPath records the chains of a path, hermes has no notion of paths itself
+type hermesPath struct {
+	srcChainID string
+	dstChainID string
+}
+
+type hermesConfig struct {
+	Global    hermesGlobalConfig    `toml:"global"`
+	Telemetry hermesTelemetryConfig `toml:"telemetry"`
+	Chains    []hermesChainConfig   `toml:"chains"`
+}
+
+type hermesGlobalConfig struct {
+	Strategy string `toml:"strategy"`
+	LogLevel string `toml:"log_level"`
+}
+
+type hermesTelemetryConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Host    string `toml:"host"`
+	Port    int    `toml:"port"`
+}
+
+type hermesChainConfig struct {
+	ID             string               `toml:"id"`
+	RPCAddr        string               `toml:"rpc_addr"`
+	GRPCAddr       string               `toml:"grpc_addr"`
+	WebsocketAddr  string               `toml:"websocket_addr"`
+	RPCTimeout     string               `toml:"rpc_timeout"`
+	AccountPrefix  string               `toml:"account_prefix"`
+	KeyName        string               `toml:"key_name"`
+	StorePrefix    string               `toml:"store_prefix"`
+	MaxGas         int                  `toml:"max_gas"`
+	GasPrice       hermesGasPrice       `toml:"gas_price"`
+	ClockDrift     string               `toml:"clock_drift"`
+	TrustingPeriod string               `toml:"trusting_period"`
+	TrustThreshold hermesTrustThreshold `toml:"trust_threshold"`
+}
+
+type hermesGasPrice struct {
+	Price float64 `toml:"price"`
+	Denom string  `toml:"denom"`
+}
+
+type hermesTrustThreshold struct {
+	Numerator   string `toml:"numerator"`
+	Denominator string `toml:"denominator"`
+}
+
+// hermesChannelEnd is the subset of the `hermes query channel end` json result needed to find the counterparty
+type hermesChannelEnd struct {
+	Result struct {
+		Remote struct {
+			PortID    string `json:"port_id"`
+			ChannelID string `json:"channel_id"`
+		} `json:"remote"`
+	} `json:"result"`
+	Status string `json:"status"`
+}
+
+// NewHermesRelayer creates a hermes relayer whose containers are attached to the given network
+func NewHermesRelayer(t *testing.T, home string, pool *dockertest.Pool, networkID string) *HermesRelayer {
+	dr := NewDockerRelayer(t, "hermes", home, hermesHome, hermesRepository, hermesVersion, pool, networkID)
+	// hermes keeps its config and keys under $HOME/.hermes
+	dr.Env = []string{fmt.Sprintf("HOME=%s", hermesHome)}
+	return &HermesRelayer{DockerRelayer: dr, chains: make(map[string]*TestChain), paths: make(map[string]hermesPath)}
+}
+
+// ConfigPath returns the host path of the hermes config file
+func (r *HermesRelayer) ConfigPath() string {
+	return path.Join(r.Dir(), ".hermes", "config.toml")
+}
+
+// WriteConfig generates the hermes config.toml from the configured chains
+func (r *HermesRelayer) WriteConfig() error {
+	cfg := hermesConfig{
+		Global:    hermesGlobalConfig{Strategy: "packets", LogLevel: "info"},
+		Telemetry: hermesTelemetryConfig{Enabled: false, Host: "127.0.0.1", Port: 3001},
+	}
+	for _, chain := range r.chains {
+		gasPrice, err := sdk.ParseDecCoin(chain.Chain.GasPrices)
+		if err != nil {
+			return fmt.Errorf("invalid gas prices for chain %s: %w", chain.ChainID, err)
+		}
+		price, err := gasPrice.Amount.Float64()
+		if err != nil {
+			return err
+		}
+		host := chain.RelayerNode().Name()
+		cfg.Chains = append(cfg.Chains, hermesChainConfig{
+			ID:             chain.ChainID,
+			RPCAddr:        fmt.Sprintf("http://%s:26657", host),
+			GRPCAddr:       fmt.Sprintf("http://%s:9090", host),
+			WebsocketAddr:  fmt.Sprintf("ws://%s:26657/websocket", host),
+			RPCTimeout:     "10s",
+			AccountPrefix:  chain.Chain.Bech32Prefix,
+			KeyName:        hermesKey,
+			StorePrefix:    "ibc",
+			MaxGas:         3000000,
+			GasPrice:       hermesGasPrice{Price: price, Denom: gasPrice.Denom},
+			ClockDrift:     "5s",
+			TrustingPeriod: "14days",
+			TrustThreshold: hermesTrustThreshold{Numerator: "1", Denominator: "3"},
+		})
+	}
+
+	bz, err := toml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(path.Dir(r.ConfigPath()), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.ConfigPath(), bz, 0644) //nolint
+}
+
+// AddChainConfiguration implements Relayer
+func (r *HermesRelayer) AddChainConfiguration(ctx context.Context, chain *TestChain) error {
+	r.chains[chain.ChainID] = chain
+	return r.WriteConfig()
+}
+
+// RestoreKey implements Relayer
+func (r *HermesRelayer) RestoreKey(ctx context.Context, chainID, mnemonic string) error {
+	command := []string{"hermes", "keys", "restore", chainID, "--mnemonic", mnemonic}
+	return handleNodeJobError(r.RelayerJob(ctx, command))
+}
+
+// GeneratePath implements Relayer
+func (r *HermesRelayer) GeneratePath(ctx context.Context, srcChainID, dstChainID, pathName string) error {
+	for _, chainID := range []string{srcChainID, dstChainID} {
+		if _, ok := r.chains[chainID]; !ok {
+			return fmt.Errorf("chain %s is not configured for hermes", chainID)
+		}
+	}
+	r.paths[pathName] = hermesPath{srcChainID: srcChainID, dstChainID: dstChainID}
+	return nil
+}
+
+// LinkPath implements Relayer, hermes creates the clients and connection along with the channel
+func (r *HermesRelayer) LinkPath(ctx context.Context, pathName string) error {
+	p, ok := r.paths[pathName]
+	if !ok {
+		return fmt.Errorf("unknown path %s", pathName)
+	}
+	command := []string{"hermes", "create", "channel", p.srcChainID, p.dstChainID,
+		"--port-a", "transfer",
+		"--port-b", "transfer",
+	}
+	return handleNodeJobError(r.RelayerJob(ctx, command))
+}
+
+// StartRelayer implements Relayer, the hermes daemon relays on every configured chain
+func (r *HermesRelayer) StartRelayer(ctx context.Context, pathName string) error {
+	if _, ok := r.paths[pathName]; !ok {
+		return fmt.Errorf("unknown path %s", pathName)
+	}
+	return r.StartRelayerContainer([]string{"hermes", "start"})
+}
+
+// StopRelayer implements Relayer
+func (r *HermesRelayer) StopRelayer(ctx context.Context) error {
+	return r.StopRelayerContainer(ctx)
+}
+
+// FlushPackets implements Relayer
+func (r *HermesRelayer) FlushPackets(ctx context.Context, pathName, channelID string) error {
+	p, ok := r.paths[pathName]
+	if !ok {
+		return fmt.Errorf("unknown path %s", pathName)
+	}
+	counterpartyChannelID, err := r.CounterpartyChannel(ctx, p.srcChainID, "transfer", channelID)
+	if err != nil {
+		return err
+	}
+	command := []string{"hermes", "tx", "raw", "packet-recv", p.dstChainID, p.srcChainID, "transfer", channelID}
+	if err := handleNodeJobError(r.RelayerJob(ctx, command)); err != nil {
+		return err
+	}
+	command = []string{"hermes", "tx", "raw", "packet-ack", p.srcChainID, p.dstChainID, "transfer", counterpartyChannelID}
+	return handleNodeJobError(r.RelayerJob(ctx, command))
+}
+
+// CounterpartyChannel queries the channel on the other end of the given channel
+func (r *HermesRelayer) CounterpartyChannel(ctx context.Context, chainID, portID, channelID string) (string, error) {
+	command := []string{"hermes", "--json", "query", "channel", "end", chainID, portID, channelID}
+	res, err := r.RelayerJob(ctx, command)
+	if err := handleNodeJobError(res, err); err != nil {
+		return "", err
+	}
+	// hermes prints its result as the last json line of the output
+	lines := strings.Split(strings.TrimSpace(res.Stdout), "\n")
+	var end hermesChannelEnd
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &end); err != nil {
+		return "", fmt.Errorf("failed to decode hermes channel end: %w", err)
+	}
+	if end.Status != "success" {
+		return "", fmt.Errorf("hermes query channel end returned status %s", end.Status)
+	}
+	return end.Result.Remote.ChannelID, nil
+}
@@ -0,0 +1,162 @@
+package test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avast/retry-go"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	transferPort    = "transfer"
+	transferVersion = "ics20-1"
+)
+
+// TransferTimeout holds the packet timeouts of an ICS-20 transfer relative to the destination chain,
+// the zero value uses the default timeouts of the chain binary and a zero field disables that timeout
+type TransferTimeout struct {
+	Height    uint64
+	Timestamp time.Duration
+}
+
+// SendIBCTransfer sends an ICS-20 transfer from a key in the node's keyring over the given channel
+// of the transfer port, using the default timeouts of the chain binary
+func (tn *TestNode) SendIBCTransfer(ctx context.Context, channelID, keyName, toAddr string, amount sdk.Coin) error {
+	return tn.SendIBCTransferWithTimeout(ctx, channelID, keyName, toAddr, amount, TransferTimeout{})
+}
+
+// SendIBCTransferWithTimeout sends an ICS-20 transfer with the given packet timeouts
+func (tn *TestNode) SendIBCTransferWithTimeout(ctx context.Context, channelID, keyName, toAddr string,
+	amount sdk.Coin, timeout TransferTimeout) error {
+	args := []string{"ibc-transfer", "transfer", transferPort, channelID, toAddr, amount.String()}
+	if timeout != (TransferTimeout{}) {
+		args = append(args,
+			"--packet-timeout-height", fmt.Sprintf("0-%d", timeout.Height),
+			"--packet-timeout-timestamp", strconv.FormatInt(timeout.Timestamp.Nanoseconds(), 10),
+		)
+	}
+	return handleNodeJobError(tn.NodeJob(ctx, tn.TxCommand(keyName, args...)))
+}
+
+// GetBalance returns the balance of an address for a denom on the node's chain
+func (tn *TestNode) GetBalance(ctx context.Context, address, denom string) (sdk.Coin, error) {
+	res, err := banktypes.NewQueryClient(tn.CliContext()).Balance(ctx, &banktypes.QueryBalanceRequest{
+		Address: address,
+		Denom:   denom,
+	})
+	if err != nil {
+		return sdk.Coin{}, err
+	}
+	return *res.Balance, nil
+}
+
+// GetIBCBalance returns the balance of an address for the voucher of a base denom received
+// over the given channel of the node's chain
+func (tn *TestNode) GetIBCBalance(ctx context.Context, channelID, address, baseDenom string) (sdk.Coin, error) {
+	return tn.GetBalance(ctx, address, GetIBCDenom(transferPort, channelID, baseDenom))
+}
+
+// EscrowAddress returns the bech32 address of the transfer escrow account for a channel on the node's chain
+func (tn *TestNode) EscrowAddress(channelID string) (string, error) {
+	return sdk.Bech32ifyAddressBytes(tn.Chain.Bech32Prefix, GetEscrowAddress(transferPort, channelID))
+}
+
+// RequireEscrowBalance asserts that the escrow account of a channel on the node's chain holds exactly the given amount
+func (tn *TestNode) RequireEscrowBalance(ctx context.Context, channelID string, expected sdk.Coin) {
+	escrow, err := tn.EscrowAddress(channelID)
+	require.NoError(tn.t, err)
+	balance, err := tn.GetBalance(ctx, escrow, expected.Denom)
+	require.NoError(tn.t, err)
+	require.True(tn.t, expected.IsEqual(balance), "escrow %s on %s holds %s, expected %s",
+		escrow, tn.ChainID, balance, expected)
+}
+
+// WaitForPacketExpiry waits until the node's chain, which is the packet's destination, has passed
+// the timeout height or timestamp of the packet so that the packet can be timed out
+func (tn *TestNode) WaitForPacketExpiry(ctx context.Context, packet Packet, timeout time.Duration) error {
+	timeoutHeight, timeoutTimestamp, err := packet.timeout()
+	if err != nil {
+		return err
+	}
+	if timeoutHeight == 0 && timeoutTimestamp == 0 {
+		return fmt.Errorf("packet %d has no timeout", packet.Sequence)
+	}
+
+	return retry.Do(func() error {
+		stat, err := tn.Client.Status(ctx)
+		if err != nil {
+			return err
+		}
+		if timeoutHeight != 0 && stat.SyncInfo.LatestBlockHeight >= timeoutHeight {
+			return nil
+		}
+		if timeoutTimestamp != 0 && stat.SyncInfo.LatestBlockTime.UnixNano() >= timeoutTimestamp {
+			return nil
+		}
+		return fmt.Errorf("packet %d not expired on %s at block %d", packet.Sequence, tn.ChainID,
+			stat.SyncInfo.LatestBlockHeight)
+	}, retry.Context(ctx), retry.Delay(time.Second), retry.DelayType(retry.FixedDelay),
+		retry.Attempts(uint(timeout/time.Second)+1), retry.LastErrorOnly(true))
+}
+
+// timeout parses the timeout height, without its revision number, and the timeout timestamp of the packet,
+// a timeout that is not set is returned as zero
+func (p Packet) timeout() (height, timestamp int64, err error) {
+	if p.TimeoutHeight != "" {
+		parts := strings.Split(p.TimeoutHeight, "-")
+		if len(parts) != 2 {
+			return 0, 0, fmt.Errorf("invalid timeout height %q of packet %d", p.TimeoutHeight, p.Sequence)
+		}
+		if height, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid timeout height %q of packet %d: %w", p.TimeoutHeight, p.Sequence, err)
+		}
+	}
+	if p.TimeoutTimestamp != "" {
+		if timestamp, err = strconv.ParseInt(p.TimeoutTimestamp, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid timeout timestamp %q of packet %d: %w", p.TimeoutTimestamp, p.Sequence, err)
+		}
+	}
+	return height, timestamp, nil
+}
+
+// GetIBCDenom returns the ibc/<hash> voucher denom for a base denom received over the given port and channel
+func GetIBCDenom(portID, channelID, baseDenom string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s", portID, channelID, baseDenom)))
+	return fmt.Sprintf("ibc/%s", strings.ToUpper(hex.EncodeToString(hash[:])))
+}
+
+// GetEscrowAddress returns the address of the ICS-20 escrow account for a port and channel
+func GetEscrowAddress(portID, channelID string) sdk.AccAddress {
+	preImage := append([]byte(transferVersion), 0)
+	preImage = append(preImage, []byte(fmt.Sprintf("%s/%s", portID, channelID))...)
+	hash := sha256.Sum256(preImage)
+	return sdk.AccAddress(hash[:20])
+}
+
+func TestGetIBCDenom(t *testing.T) {
+	require.Equal(t, "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+		GetIBCDenom("transfer", "channel-0", "uatom"))
+}
+
+func TestPacketTimeout(t *testing.T) {
+	height, timestamp, err := Packet{TimeoutHeight: "1-120", TimeoutTimestamp: "0"}.timeout()
+	require.NoError(t, err)
+	require.Equal(t, int64(120), height)
+	require.Equal(t, int64(0), timestamp)
+
+	_, _, err = Packet{TimeoutHeight: "120"}.timeout()
+	require.Error(t, err)
+	_, _, err = Packet{TimeoutHeight: "1-abc"}.timeout()
+	require.Error(t, err)
+	_, _, err = Packet{TimeoutHeight: "0-0", TimeoutTimestamp: "soon"}.timeout()
+	require.Error(t, err)
+}
@@ -1,20 +1,28 @@
 package test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/simapp/params"
 	"github.com/ory/dockertest"
 	"github.com/ory/dockertest/docker"
+	tmconfig "github.com/tendermint/tendermint/config"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
 )
 
 // ChainType represents the type of chain to instantiate
 type ChainType struct {
-	Repository string
-	Version    string
-	Bin        string
-	Ports      map[docker.Port]struct{}
+	Name         string
+	Repository   string
+	Version      string
+	Bin          string
+	Bech32Prefix string
+	Denom        string
+	GasPrices    string
+	HomeDir      string
+	Ports        map[docker.Port]struct{}
 }
 
 // TestNode represents a node in the test network that is being created
@@ -25,11 +33,20 @@ type TestNode struct {
 	Chain        *ChainType
 	GenesisCoins string
 	Validator    bool
-	Pool         *dockertest.Pool
-	Client       rpcclient.Client
-	Container    *docker.Container
-	t            *testing.T
-	ec           params.EncodingConfig
+	AppConfig    AppConfig
+	// TMConfigMutators are applied to the node's config.toml after the framework's defaults
+	TMConfigMutators []TMConfigMutator
+	LogOutput        bool
+	Cosmovisor       *CosmovisorConfig
+	Pool             *dockertest.Pool
+	NetworkID        string
+	Client           rpcclient.Client
+	Container        *docker.Container
+	t                *testing.T
+	ec               params.EncodingConfig
+	chainAppConfig   AppConfig
+	stopLogs         func()
+	netem            *netemSidecar
 }
 
 type ContainerPort struct {
@@ -39,3 +56,101 @@ type ContainerPort struct {
 }
 
 type Hosts []ContainerPort
+
+// AppConfig holds typed overrides for a node's app.toml, unset fields keep the value written by init
+type AppConfig struct {
+	MinimumGasPrices   string
+	Pruning            string
+	PruningKeepRecent  string
+	PruningInterval    string
+	APIEnable          *bool
+	GRPCEnable         *bool
+	SnapshotInterval   *uint64
+	SnapshotKeepRecent *uint32
+	TelemetryEnable    *bool
+}
+
+// TMConfigMutator modifies a node's Tendermint config before it is written to config.toml
+type TMConfigMutator func(cfg *tmconfig.Config)
+
+// CosmovisorConfig runs a node under cosmovisor, which switches to the staged upgrade binary
+// when the chain halts at an upgrade height, instead of starting the chain binary directly
+type CosmovisorConfig struct {
+	// Binary is the host path of a cosmovisor binary that is able to run in the chain images,
+	// such as a statically linked build, it is copied into the node's home folder
+	Binary string
+}
+
+// ChainConfig describes a chain to be created by SetupTestChains
+type ChainConfig struct {
+	ChainType     *ChainType
+	ChainID       string
+	NumValidators int
+	NumFullNodes  int
+	GenesisHooks  GenesisHooks
+
+	// NodeChainTypes overrides the chain type of individual nodes by index, validators come first,
+	// so that nodes of the chain can run different versions
+	NodeChainTypes map[int]*ChainType
+
+	// AppConfig is applied to the app.toml of every node of the chain, set fields of the AppConfig
+	// of individual nodes before the chain is started to override them per node
+	AppConfig AppConfig
+
+	// TMConfigMutators are applied to the config.toml of every node of the chain, append to
+	// the TMConfigMutators of individual nodes before the chain is started to add per node changes
+	TMConfigMutators []TMConfigMutator
+
+	// VotingPeriod overrides the gov deposit and voting periods at genesis so proposals finish quickly
+	VotingPeriod time.Duration
+
+	// Cosmovisor runs every node of the chain under cosmovisor
+	Cosmovisor *CosmovisorConfig
+
+	// LogNodeOutput writes the output of the chain's nodes to the test log in addition to their log files
+	LogNodeOutput bool
+}
+
+// TestChain represents a single chain with its own validator set and full nodes
+type TestChain struct {
+	ChainID      string
+	Chain        *ChainType
+	Validators   TestNodes
+	FullNodes    TestNodes
+	GenesisHooks GenesisHooks
+}
+
+// Relayer represents a relayer implementation that relays packets between test chains
+type Relayer interface {
+	// AddChainConfiguration configures the relayer to connect to the given chain
+	AddChainConfiguration(ctx context.Context, chain *TestChain) error
+
+	// RestoreKey restores the relayer's signing key for a chain from a mnemonic
+	RestoreKey(ctx context.Context, chainID, mnemonic string) error
+
+	// GeneratePath creates a path between two configured chains on the transfer port
+	GeneratePath(ctx context.Context, srcChainID, dstChainID, pathName string) error
+
+	// LinkPath creates the clients, connection and channel for a path
+	LinkPath(ctx context.Context, pathName string) error
+
+	// StartRelayer starts the relayer daemon relaying packets on a path
+	StartRelayer(ctx context.Context, pathName string) error
+
+	// StopRelayer stops the relayer daemon
+	StopRelayer(ctx context.Context) error
+
+	// FlushPackets relays all pending packets and acknowledgements on a path
+	// for the given channel on the path's source chain
+	FlushPackets(ctx context.Context, pathName, channelID string) error
+}
+
+// GenesisHook modifies the json genesis file of a chain
+type GenesisHook func(genesis []byte) ([]byte, error)
+
+// GenesisHooks are run on the genesis file of the first validator before and after the gentxs are collected,
+// the resulting genesis is then copied to every other node of the chain
+type GenesisHooks struct {
+	PreCollectGentxs  []GenesisHook
+	PostCollectGentxs []GenesisHook
+}
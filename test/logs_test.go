@@ -0,0 +1,205 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/docker"
+	"github.com/stretchr/testify/require"
+)
+
+// artifactsDirEnv is the environment variable that overrides the directory test artifacts are written to
+const artifactsDirEnv = "IBC_TEST_ARTIFACTS_DIR"
+
+// ArtifactsDir returns the directory the artifacts of a test, such as node logs, are written to,
+// it is kept after the test so the artifacts can be inspected
+func ArtifactsDir(t *testing.T) string {
+	dir := os.Getenv(artifactsDirEnv)
+	if dir == "" {
+		dir = path.Join(os.TempDir(), "ibc-test-artifacts")
+	}
+	return path.Join(dir, DockerName(t.Name()))
+}
+
+// LogFilePath returns the path of the file the node's container output is streamed to
+func (tn *TestNode) LogFilePath() string {
+	return path.Join(ArtifactsDir(tn.t), fmt.Sprintf("%s.log", tn.Name()))
+}
+
+// StreamLogs streams the stdout and stderr of the node container to the node's log file, and to the test log
+// if the node's LogOutput is set, until the test finishes, the stream follows the container by name so it
+// resumes after the container is restarted or recreated, calling it again while streaming is a no-op
+func (tn *TestNode) StreamLogs() error {
+	if tn.stopLogs != nil {
+		return nil
+	}
+	if err := os.MkdirAll(ArtifactsDir(tn.t), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(tn.LogFilePath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644) //nolint
+	if err != nil {
+		return err
+	}
+	tn.t.Logf("{%s} streaming logs to %s", tn.Name(), tn.LogFilePath())
+
+	var w io.Writer = f
+	var tw *testLogWriter
+	if tn.LogOutput {
+		tw = &testLogWriter{t: tn.t, prefix: tn.Name()}
+		w = io.MultiWriter(f, tw)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tn.followLogs(ctx, w)
+	}()
+	tn.stopLogs = func() {
+		cancel()
+		<-done
+		if tw != nil {
+			tw.Flush()
+		}
+		_ = f.Close()
+	}
+	tn.t.Cleanup(tn.stopLogs)
+	return nil
+}
+
+// followLogs follows the container logs, reattaching whenever the container stops or is replaced and
+// resuming strictly after the last line that was written
+func (tn *TestNode) followLogs(ctx context.Context, w io.Writer) {
+	rw := &resumeLogWriter{w: w}
+	for {
+		var since int64
+		if !rw.last.IsZero() {
+			since = rw.last.Unix()
+		}
+		_ = tn.Pool.Client.Logs(docker.LogsOptions{
+			Context:      ctx,
+			Container:    tn.Name(),
+			OutputStream: rw,
+			ErrorStream:  rw,
+			Stdout:       true,
+			Stderr:       true,
+			Follow:       true,
+			Timestamps:   true,
+			Since:        since,
+		})
+		rw.Flush()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// resumeLogWriter strips the docker timestamps from log lines and drops lines that are not newer than the
+// last line written, since docker only resumes logs at the start of a second
+type resumeLogWriter struct {
+	w    io.Writer
+	last time.Time
+	mu   sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (w *resumeLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if err := w.writeLine(w.buf.Next(i + 1)); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// Flush writes any remaining partial line
+func (w *resumeLogWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.buf.Len() > 0 {
+		_ = w.writeLine(append(w.buf.Next(w.buf.Len()), '\n'))
+	}
+}
+
+func (w *resumeLogWriter) writeLine(line []byte) error {
+	i := bytes.IndexByte(line, ' ')
+	if i < 0 {
+		_, err := w.w.Write(line)
+		return err
+	}
+	ts, err := time.Parse(time.RFC3339Nano, string(line[:i]))
+	if err != nil {
+		_, err := w.w.Write(line)
+		return err
+	}
+	if !ts.After(w.last) {
+		return nil
+	}
+	w.last = ts
+	_, err = w.w.Write(line[i+1:])
+	return err
+}
+
+// testLogWriter writes complete lines to the test log with a prefix
+type testLogWriter struct {
+	t      *testing.T
+	prefix string
+	mu     sync.Mutex
+	buf    bytes.Buffer
+}
+
+func (w *testLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.t.Logf("{%s} %s", w.prefix, w.buf.Next(i + 1)[:i])
+	}
+}
+
+// Flush writes any remaining partial line to the test log
+func (w *testLogWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.buf.Len() > 0 {
+		w.t.Logf("{%s} %s", w.prefix, w.buf.String())
+		w.buf.Reset()
+	}
+}
+
+func TestResumeLogWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &resumeLogWriter{w: &out}
+	_, err := w.Write([]byte("2022-01-02T15:04:05.100000000Z first\n2022-01-02T15:04:05.2000"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("00000Z second\n"))
+	require.NoError(t, err)
+
+	// reattaching replays the lines of the same second
+	_, err = w.Write([]byte("2022-01-02T15:04:05.100000000Z first\n2022-01-02T15:04:05.200000000Z second\n"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("2022-01-02T15:04:05.300000000Z third\n2022-01-02T15:04:06.000000000Z partial"))
+	require.NoError(t, err)
+	w.Flush()
+
+	require.Equal(t, "first\nsecond\nthird\npartial\n", out.String())
+}
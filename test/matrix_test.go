@@ -0,0 +1,127 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/ory/dockertest"
+	"github.com/stretchr/testify/require"
+)
+
+const matrixPathName = "matrix-path"
+
+// RelayerFactory creates a relayer implementation whose containers are attached to the given network
+type RelayerFactory struct {
+	Name string
+	New  func(t *testing.T, home string, pool *dockertest.Pool, networkID string) Relayer
+}
+
+// RelayerImplementations are all of the relayer implementations supported by the framework
+var RelayerImplementations = []RelayerFactory{
+	{Name: "rly", New: func(t *testing.T, home string, pool *dockertest.Pool, networkID string) Relayer {
+		return NewCosmosRelayer(t, home, pool, networkID)
+	}},
+	{Name: "hermes", New: func(t *testing.T, home string, pool *dockertest.Pool, networkID string) Relayer {
+		return NewHermesRelayer(t, home, pool, networkID)
+	}},
+	{Name: "tsrelayer", New: func(t *testing.T, home string, pool *dockertest.Pool, networkID string) Relayer {
+		return NewTsRelayer(t, home, pool, networkID)
+	}},
+}
+
+// MatrixScenario is an IBC scenario run against two linked chains, the relayer has already
+// been configured and has linked a transfer channel on the given path but is not started
+type MatrixScenario func(t *testing.T, ctx context.Context, relayer Relayer, pathName string, chainA, chainB *TestChain)
+
+// CompatibilityMatrix runs a scenario for every combination of relayer implementation,
+// chain A version and chain B version
+type CompatibilityMatrix struct {
+	Relayers       []RelayerFactory
+	ChainA         string
+	ChainAVersions []string
+	ChainB         string
+	ChainBVersions []string
+	NumValidators  int
+	NumFullNodes   int
+
+	// ResultsFile is an optional file the pass/fail table is written to
+	ResultsFile string
+}
+
+// matrixResult is the outcome of a single combination of the matrix
+type matrixResult struct {
+	relayer string
+	chainA  string
+	chainB  string
+	passed  bool
+}
+
+// Run runs the scenario as a subtest for each combination of the matrix and reports a pass/fail table
+func (m CompatibilityMatrix) Run(t *testing.T, scenario MatrixScenario) {
+	relayers := m.Relayers
+	if len(relayers) == 0 {
+		relayers = RelayerImplementations
+	}
+	numValidators := m.NumValidators
+	if numValidators == 0 {
+		numValidators = 1
+	}
+
+	var results []matrixResult
+	for _, rf := range relayers {
+		for _, versionA := range m.ChainAVersions {
+			for _, versionB := range m.ChainBVersions {
+				rf, versionA, versionB := rf, versionA, versionB
+				res := matrixResult{
+					relayer: rf.Name,
+					chainA:  fmt.Sprintf("%s:%s", m.ChainA, versionA),
+					chainB:  fmt.Sprintf("%s:%s", m.ChainB, versionB),
+				}
+				res.passed = t.Run(fmt.Sprintf("%s_%s_%s", rf.Name, versionA, versionB), func(t *testing.T) {
+					chainTypeA, err := GetChain(m.ChainA, versionA)
+					require.NoError(t, err)
+					chainTypeB, err := GetChain(m.ChainB, versionB)
+					require.NoError(t, err)
+
+					ctx, home, pool, network, chains := SetupTestChains(t,
+						ChainConfig{ChainType: chainTypeA, NumValidators: numValidators, NumFullNodes: m.NumFullNodes},
+						ChainConfig{ChainType: chainTypeB, NumValidators: numValidators, NumFullNodes: m.NumFullNodes},
+					)
+
+					StartChains(t, ctx, network, chains...)
+
+					relayer := rf.New(t, home, pool, network.ID)
+					SetupRelayer(t, ctx, relayer, matrixPathName, chains[0], chains[1])
+
+					scenario(t, ctx, relayer, matrixPathName, chains[0], chains[1])
+				})
+				results = append(results, res)
+			}
+		}
+	}
+
+	table := matrixTable(results)
+	t.Logf("compatibility matrix results:\n%s", table)
+	if m.ResultsFile != "" {
+		require.NoError(t, ioutil.WriteFile(m.ResultsFile, []byte(table), 0644)) //nolint
+	}
+}
+
+func matrixTable(results []matrixResult) string {
+	bldr := new(strings.Builder)
+	w := tabwriter.NewWriter(bldr, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RELAYER\tCHAIN A\tCHAIN B\tRESULT")
+	for _, res := range results {
+		status := "PASS"
+		if !res.passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", res.relayer, res.chainA, res.chainB, status)
+	}
+	_ = w.Flush()
+	return bldr.String()
+}
@@ -0,0 +1,163 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ory/dockertest/docker"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	netemRepository = "nicolaka/netshoot"
+	netemVersion    = "v0.8"
+)
+
+// NetemConfig describes the link conditions emulated on every interface of a node, the zero value
+// removes any emulation
+type NetemConfig struct {
+	Delay  time.Duration
+	Jitter time.Duration
+	// Loss is the percentage of packets dropped
+	Loss float64
+	// Rate limits the bandwidth in tc units, such as 1mbit, empty means unlimited
+	Rate string
+}
+
+// args returns the tc netem arguments for the config
+func (c NetemConfig) args() []string {
+	var args []string
+	if c.Delay != 0 || c.Jitter != 0 {
+		args = append(args, "delay", fmt.Sprintf("%dus", c.Delay.Microseconds()))
+		if c.Jitter != 0 {
+			args = append(args, fmt.Sprintf("%dus", c.Jitter.Microseconds()))
+		}
+	}
+	if c.Loss != 0 {
+		args = append(args, "loss", fmt.Sprintf("%s%%", strconv.FormatFloat(c.Loss, 'f', -1, 64)))
+	}
+	if c.Rate != "" {
+		args = append(args, "rate", c.Rate)
+	}
+	return args
+}
+
+// netemSidecar is a container with the tc tool that shares the network namespace of a node container
+type netemSidecar struct {
+	containerID     string
+	nodeContainerID string
+}
+
+// SetNetem applies the link conditions to the node's network interfaces, it can be called again during
+// a test to change them, the conditions are lost when the node container is recreated
+func (tn *TestNode) SetNetem(ctx context.Context, cfg NetemConfig) error {
+	if err := tn.ensureNetemSidecar(); err != nil {
+		return err
+	}
+	script := "for dev in $(ls /sys/class/net | grep -v '^lo$'); do tc qdisc del dev $dev root 2>/dev/null; done"
+	if cfg != (NetemConfig{}) {
+		script = fmt.Sprintf("for dev in $(ls /sys/class/net | grep -v '^lo$'); do tc qdisc replace dev $dev root netem %s || exit 1; done",
+			strings.Join(cfg.args(), " "))
+	}
+	tn.t.Logf("{%s} netem -> %+v", tn.Name(), cfg)
+	_, _, err := execInContainer(ctx, tn.Pool, tn.netem.containerID, "", []string{"sh", "-c", script})
+	return err
+}
+
+// ClearNetem removes any link conditions from the node's network interfaces
+func (tn *TestNode) ClearNetem(ctx context.Context) error {
+	return tn.SetNetem(ctx, NetemConfig{})
+}
+
+// ensureNetemSidecar starts a sidecar in the network namespace of the current node container,
+// replacing a sidecar left over from a previous node container
+func (tn *TestNode) ensureNetemSidecar() error {
+	if tn.netem != nil && tn.netem.nodeContainerID == tn.Container.ID {
+		return nil
+	}
+	if tn.netem != nil {
+		_ = tn.Pool.Client.RemoveContainer(docker.RemoveContainerOptions{ID: tn.netem.containerID, Force: true})
+		tn.netem = nil
+	}
+
+	image := fmt.Sprintf("%s:%s", netemRepository, netemVersion)
+	if _, err := tn.Pool.Client.InspectImage(image); err != nil {
+		if err := tn.Pool.Client.PullImage(docker.PullImageOptions{
+			Repository: netemRepository,
+			Tag:        netemVersion,
+		}, docker.AuthConfiguration{}); err != nil {
+			return fmt.Errorf("failed to pull %s: %w", image, err)
+		}
+	}
+
+	cont, err := tn.Pool.Client.CreateContainer(docker.CreateContainerOptions{
+		Name: fmt.Sprintf("%s-netem", tn.Name()),
+		Config: &docker.Config{
+			Image:  image,
+			Cmd:    []string{"sleep", "infinity"},
+			Labels: testLabels(tn.t),
+		},
+		HostConfig: &docker.HostConfig{
+			NetworkMode: fmt.Sprintf("container:%s", tn.Container.ID),
+			CapAdd:      []string{"NET_ADMIN"},
+			AutoRemove:  true,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := tn.Pool.Client.StartContainer(cont.ID, nil); err != nil {
+		return err
+	}
+	tn.netem = &netemSidecar{containerID: cont.ID, nodeContainerID: tn.Container.ID}
+	return nil
+}
+
+// SetNetem applies the same link conditions to every node
+func (tn TestNodes) SetNetem(ctx context.Context, cfg NetemConfig) error {
+	for _, n := range tn {
+		if err := n.SetNetem(ctx, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestNetemArgs(t *testing.T) {
+	require.Equal(t, []string{"delay", "100000us", "20000us", "loss", "0.5%", "rate", "1mbit"},
+		NetemConfig{Delay: 100 * time.Millisecond, Jitter: 20 * time.Millisecond, Loss: 0.5, Rate: "1mbit"}.args())
+	require.Equal(t, []string{"loss", "10%"}, NetemConfig{Loss: 10}.args())
+}
+
+func TestTransferOverSlowLinks(t *testing.T) {
+	CompatibilityMatrix{
+		Relayers:       RelayerImplementations[:1],
+		ChainA:         "gaia",
+		ChainAVersions: []string{"v6.0.0-rocks"},
+		ChainB:         "gaia",
+		ChainBVersions: []string{"v6.0.0-rocks"},
+	}.Run(t, func(t *testing.T, ctx context.Context, relayer Relayer, pathName string, chainA, chainB *TestChain) {
+		wan := NetemConfig{Delay: 200 * time.Millisecond, Jitter: 50 * time.Millisecond, Loss: 1, Rate: "1mbit"}
+		require.NoError(t, chainA.Nodes().SetNetem(ctx, wan))
+		require.NoError(t, chainB.Nodes().SetNetem(ctx, wan))
+		require.NoError(t, relayer.StartRelayer(ctx, pathName))
+		t.Cleanup(func() { _ = relayer.StopRelayer(ctx) })
+
+		src, dst := chainA.Validators[0], chainB.Validators[0]
+		receiver, err := dst.AccountAddress(valKey)
+		require.NoError(t, err)
+		amount := sdk.NewInt64Coin(chainA.Chain.Denom, 1000)
+		require.NoError(t, src.SendIBCTransfer(ctx, "channel-0", valKey, receiver, amount))
+		packet, err := src.LatestSentPacket(ctx, "transfer", "channel-0")
+		require.NoError(t, err)
+
+		ack, err := WaitForPacketAck(ctx, src, dst, packet, 3*time.Minute)
+		require.NoError(t, err)
+		require.True(t, ack.Success(), ack.Error)
+	})
+}
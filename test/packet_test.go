@@ -0,0 +1,286 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// Packet identifies an IBC packet by its sequence and the ports and channels on both ends
+type Packet struct {
+	Sequence         uint64
+	SourcePort       string
+	SourceChannel    string
+	DestPort         string
+	DestChannel      string
+	TimeoutHeight    string
+	TimeoutTimestamp string
+	Data             string
+}
+
+// PacketState is the state of a packet as stored on the source and destination chains
+type PacketState struct {
+	// Committed is true while the source chain holds the packet commitment,
+	// it is deleted once the packet is acknowledged or timed out
+	Committed bool
+	// Received is true once the destination chain has written a packet receipt
+	Received bool
+	// Acknowledged is true once the destination chain has written the acknowledgement
+	Acknowledged bool
+}
+
+// Acknowledgement is a decoded packet acknowledgement, error acknowledgements only have Error set
+type Acknowledgement struct {
+	Result []byte `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Success returns true if the acknowledgement is not an error acknowledgement
+func (a Acknowledgement) Success() bool {
+	return a.Error == ""
+}
+
+func packetKey(prefix, portID, channelID string, sequence uint64) []byte {
+	return []byte(fmt.Sprintf("%s/ports/%s/channels/%s/sequences/%d", prefix, portID, channelID, sequence))
+}
+
+// queryIBCStore runs an ABCI query against a key of the IBC core store
+func (tn *TestNode) queryIBCStore(ctx context.Context, key []byte) ([]byte, error) {
+	res, err := tn.Client.ABCIQuery(ctx, "store/ibc/key", key)
+	if err != nil {
+		return nil, err
+	}
+	if !res.Response.IsOK() {
+		return nil, fmt.Errorf("ibc store query for %s failed: %s", key, res.Response.Log)
+	}
+	return res.Response.Value, nil
+}
+
+// PacketCommitment returns the packet commitment stored on the node's chain, which is the packet's source
+func (tn *TestNode) PacketCommitment(ctx context.Context, packet Packet) ([]byte, error) {
+	return tn.queryIBCStore(ctx, packetKey("commitments", packet.SourcePort, packet.SourceChannel, packet.Sequence))
+}
+
+// PacketReceipt returns true if the node's chain, which is the packet's destination, has received the packet
+func (tn *TestNode) PacketReceipt(ctx context.Context, packet Packet) (bool, error) {
+	receipt, err := tn.queryIBCStore(ctx, packetKey("receipts", packet.DestPort, packet.DestChannel, packet.Sequence))
+	return len(receipt) > 0, err
+}
+
+// PacketAckCommitment returns the acknowledgement commitment stored on the node's chain, which is the packet's destination
+func (tn *TestNode) PacketAckCommitment(ctx context.Context, packet Packet) ([]byte, error) {
+	return tn.queryIBCStore(ctx, packetKey("acks", packet.DestPort, packet.DestChannel, packet.Sequence))
+}
+
+// GetPacketState queries the source and destination chains for the state of a packet
+func GetPacketState(ctx context.Context, src, dst *TestNode, packet Packet) (state PacketState, err error) {
+	commitment, err := src.PacketCommitment(ctx, packet)
+	if err != nil {
+		return state, err
+	}
+	state.Committed = len(commitment) > 0
+	if state.Received, err = dst.PacketReceipt(ctx, packet); err != nil {
+		return state, err
+	}
+	ack, err := dst.PacketAckCommitment(ctx, packet)
+	state.Acknowledged = len(ack) > 0
+	return state, err
+}
+
+// packetEvents searches the node's chain for transactions with events of the given type matching the
+// packet attributes and returns the attributes of the matching events in the given "asc" or "desc" order,
+// it pages through all search results unless limit is positive and that many events have been found
+func (tn *TestNode) packetEvents(ctx context.Context, eventType string, attrs map[string]string,
+	orderBy string, limit int) ([]map[string]string, error) {
+	query := ""
+	for k, v := range attrs {
+		if query != "" {
+			query += " AND "
+		}
+		query += fmt.Sprintf("%s.%s='%s'", eventType, k, v)
+	}
+
+	var out []map[string]string
+	perPage := 100
+	for page := 1; ; page++ {
+		res, err := tn.Client.TxSearch(ctx, query, false, &page, &perPage, orderBy)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range res.Txs {
+			events := matchingEvents(tx.TxResult.Events, eventType, attrs)
+			if orderBy == "desc" {
+				for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+					events[i], events[j] = events[j], events[i]
+				}
+			}
+			out = append(out, events...)
+			if limit > 0 && len(out) >= limit {
+				return out[:limit], nil
+			}
+		}
+		if len(res.Txs) == 0 || page*perPage >= res.TotalCount {
+			return out, nil
+		}
+	}
+}
+
+// matchingEvents returns the attributes of the events of the given type that contain all attrs
+func matchingEvents(events []abcitypes.Event, eventType string, attrs map[string]string) []map[string]string {
+	var out []map[string]string
+	for _, event := range events {
+		if event.Type != eventType {
+			continue
+		}
+		found := make(map[string]string)
+		for _, attr := range event.Attributes {
+			found[string(attr.Key)] = string(attr.Value)
+		}
+		matches := true
+		for k, v := range attrs {
+			if found[k] != v {
+				matches = false
+			}
+		}
+		if matches {
+			out = append(out, found)
+		}
+	}
+	return out
+}
+
+// sentPacket converts the attributes of a send_packet event to a packet
+func sentPacket(e map[string]string) (Packet, error) {
+	sequence, err := strconv.ParseUint(e["packet_sequence"], 10, 64)
+	if err != nil {
+		return Packet{}, fmt.Errorf("invalid packet sequence %q: %w", e["packet_sequence"], err)
+	}
+	return Packet{
+		Sequence:         sequence,
+		SourcePort:       e["packet_src_port"],
+		SourceChannel:    e["packet_src_channel"],
+		DestPort:         e["packet_dst_port"],
+		DestChannel:      e["packet_dst_channel"],
+		TimeoutHeight:    e["packet_timeout_height"],
+		TimeoutTimestamp: e["packet_timeout_timestamp"],
+		Data:             e["packet_data"],
+	}, nil
+}
+
+// SentPackets returns the packets sent from the given port and channel of the node's chain in the order they were sent
+func (tn *TestNode) SentPackets(ctx context.Context, portID, channelID string) ([]Packet, error) {
+	events, err := tn.packetEvents(ctx, "send_packet", map[string]string{
+		"packet_src_port":    portID,
+		"packet_src_channel": channelID,
+	}, "asc", 0)
+	if err != nil {
+		return nil, err
+	}
+	packets := make([]Packet, len(events))
+	for i, e := range events {
+		if packets[i], err = sentPacket(e); err != nil {
+			return nil, err
+		}
+	}
+	return packets, nil
+}
+
+// LatestSentPacket returns the last packet sent from the given port and channel of the node's chain
+func (tn *TestNode) LatestSentPacket(ctx context.Context, portID, channelID string) (Packet, error) {
+	events, err := tn.packetEvents(ctx, "send_packet", map[string]string{
+		"packet_src_port":    portID,
+		"packet_src_channel": channelID,
+	}, "desc", 1)
+	if err != nil {
+		return Packet{}, err
+	}
+	if len(events) == 0 {
+		return Packet{}, fmt.Errorf("no packets sent on %s/%s of %s", portID, channelID, tn.ChainID)
+	}
+	return sentPacket(events[0])
+}
+
+// PacketAcknowledgement returns the decoded acknowledgement written for a packet by the node's chain,
+// which is the packet's destination
+func (tn *TestNode) PacketAcknowledgement(ctx context.Context, packet Packet) (*Acknowledgement, error) {
+	events, err := tn.packetEvents(ctx, "write_acknowledgement", map[string]string{
+		"packet_dst_port":    packet.DestPort,
+		"packet_dst_channel": packet.DestChannel,
+		"packet_sequence":    strconv.FormatUint(packet.Sequence, 10),
+	}, "asc", 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no acknowledgement written on %s for packet %d", tn.ChainID, packet.Sequence)
+	}
+	var ack Acknowledgement
+	if err := json.Unmarshal([]byte(events[0]["packet_ack"]), &ack); err != nil {
+		return nil, fmt.Errorf("failed to decode acknowledgement %q: %w", events[0]["packet_ack"], err)
+	}
+	return &ack, nil
+}
+
+// waitForPacketState polls the packet state until done returns true or the timeout passes
+func waitForPacketState(ctx context.Context, src, dst *TestNode, packet Packet, timeout time.Duration,
+	done func(PacketState) bool) (PacketState, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		state, err := GetPacketState(ctx, src, dst, packet)
+		if err == nil && done(state) {
+			return state, nil
+		}
+		if time.Now().After(deadline) {
+			return state, fmt.Errorf("timed out after %s waiting for packet %d on %s/%s, last state %+v (err: %v)",
+				timeout, packet.Sequence, packet.SourcePort, packet.SourceChannel, state, err)
+		}
+		select {
+		case <-ctx.Done():
+			return state, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// WaitForPacketAck waits until a packet has been received and acknowledged on the destination chain and
+// the acknowledgement has been relayed back, deleting the commitment on the source chain,
+// it returns the decoded acknowledgement which may be an error acknowledgement
+func WaitForPacketAck(ctx context.Context, src, dst *TestNode, packet Packet, timeout time.Duration) (*Acknowledgement, error) {
+	_, err := waitForPacketState(ctx, src, dst, packet, timeout, func(s PacketState) bool {
+		return s.Received && s.Acknowledged && !s.Committed
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dst.PacketAcknowledgement(ctx, packet)
+}
+
+// WaitForPacketTimeout waits until a packet has been timed out on the source chain,
+// which deletes the commitment without the destination chain ever receiving the packet
+func WaitForPacketTimeout(ctx context.Context, src, dst *TestNode, packet Packet, timeout time.Duration) error {
+	state, err := waitForPacketState(ctx, src, dst, packet, timeout, func(s PacketState) bool {
+		return !s.Committed
+	})
+	if err != nil {
+		return err
+	}
+	if state.Received {
+		return fmt.Errorf("packet %d was received on %s instead of timing out", packet.Sequence, dst.ChainID)
+	}
+	events, err := src.packetEvents(ctx, "timeout_packet", map[string]string{
+		"packet_src_port":    packet.SourcePort,
+		"packet_src_channel": packet.SourceChannel,
+		"packet_sequence":    strconv.FormatUint(packet.Sequence, 10),
+	}, "asc", 1)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("packet %d commitment was deleted on %s without a timeout", packet.Sequence, src.ChainID)
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/avast/retry-go"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ory/dockertest/docker"
+	"github.com/stretchr/testify/require"
+)
+
+// healTimeout is how long the nodes of a healed partition have to reconnect to the rest of the network
+const healTimeout = 2 * time.Minute
+
+// Partition is a split of nodes into groups that can only reach the nodes of their own group
+type Partition struct {
+	groups   []TestNodes
+	networks []*docker.Network
+}
+
+// PartitionNetwork splits the test network into partitions, the first group stays on the node's network
+// and every other group is disconnected from it and moved to a network of its own, nodes of the test
+// network that are in no group, such as relayers, stay with the first group, if the partition can not
+// be completed the groups that were already moved are moved back
+func PartitionNetwork(ctx context.Context, groups ...TestNodes) (*Partition, error) {
+	if len(groups) < 2 {
+		return nil, fmt.Errorf("a partition needs at least two groups")
+	}
+	for i, group := range groups {
+		if len(group) == 0 {
+			return nil, fmt.Errorf("partition group %d is empty", i)
+		}
+	}
+	p := &Partition{groups: groups, networks: make([]*docker.Network, len(groups))}
+	if err := p.split(ctx); err != nil {
+		if healErr := p.Heal(ctx); healErr != nil {
+			return nil, fmt.Errorf("%w, rolling back the partition failed: %v", err, healErr)
+		}
+		return nil, err
+	}
+	for i, group := range groups {
+		power, err := group.VotingPower(ctx)
+		if err != nil {
+			// the host may not be able to reach nodes that were moved off the test network
+			continue
+		}
+		group[0].t.Logf("partition group %d has %d voting power", i, power)
+	}
+	return p, nil
+}
+
+// split moves every group but the first to a network of its own
+func (p *Partition) split(ctx context.Context) error {
+	for i, group := range p.groups[1:] {
+		n0 := group[0]
+		network, err := CreateTestNetwork(n0.Pool, fmt.Sprintf("ibc-test-partition-%s", RandLowerCaseLetterString(8)), n0.t)
+		if err != nil {
+			return err
+		}
+		p.networks[i+1] = network
+		for _, n := range group {
+			if err := n.moveNetwork(ctx, n.NetworkID, network.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Heal moves every group back onto the test network, removes the partition networks and waits for
+// the moved nodes to reconnect to the first group
+func (p *Partition) Heal(ctx context.Context) error {
+	for i, group := range p.groups[1:] {
+		network := p.networks[i+1]
+		if network == nil {
+			continue
+		}
+		for _, n := range group {
+			if _, ok := n.Container.NetworkSettings.Networks[network.Name]; !ok {
+				continue
+			}
+			if err := n.moveNetwork(ctx, network.ID, n.NetworkID); err != nil {
+				return err
+			}
+		}
+		if err := group[0].Pool.Client.RemoveNetwork(network.ID); err != nil {
+			return err
+		}
+		p.networks[i+1] = nil
+	}
+	if err := p.waitForPeers(ctx); err != nil {
+		return err
+	}
+	p.groups[0][0].t.Log("partition healed")
+	return nil
+}
+
+// waitForPeers waits until every node outside the first group is connected to a node of the first group
+func (p *Partition) waitForPeers(ctx context.Context) error {
+	ids := make(map[string]bool)
+	for _, n := range p.groups[0] {
+		id, err := n.NodeID()
+		if err != nil {
+			return err
+		}
+		ids[id] = true
+	}
+	for _, group := range p.groups[1:] {
+		for _, n := range group {
+			n := n
+			if err := retry.Do(func() error {
+				info, err := n.Client.NetInfo(ctx)
+				if err != nil {
+					return err
+				}
+				for _, peer := range info.Peers {
+					if ids[string(peer.NodeInfo.DefaultNodeID)] {
+						return nil
+					}
+				}
+				return fmt.Errorf("%s did not reconnect to the rest of the network", n.Name())
+			}, retry.Context(ctx), retry.Delay(time.Second), retry.DelayType(retry.FixedDelay),
+				retry.Attempts(uint(healTimeout/time.Second)), retry.LastErrorOnly(true)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// moveNetwork disconnects the node container from one network and connects it to another, reconnecting it
+// to the first network if that fails, and points the node's client at the RPC port published after the move
+func (tn *TestNode) moveNetwork(ctx context.Context, fromID, toID string) error {
+	if err := tn.Pool.Client.DisconnectNetwork(fromID, docker.NetworkConnectionOptions{
+		Container: tn.Container.ID,
+		Force:     true,
+	}); err != nil {
+		return fmt.Errorf("failed to disconnect %s: %w", tn.Name(), err)
+	}
+	if err := tn.connectNetwork(ctx, toID); err != nil {
+		if rollbackErr := tn.connectNetwork(ctx, fromID); rollbackErr != nil {
+			tn.t.Logf("{%s} reconnecting to network %s: %v", tn.Name(), fromID, rollbackErr)
+		}
+		if refreshErr := tn.RefreshClient(); refreshErr != nil {
+			tn.t.Logf("{%s} refreshing client: %v", tn.Name(), refreshErr)
+		}
+		return fmt.Errorf("failed to connect %s: %w", tn.Name(), err)
+	}
+	return tn.RefreshClient()
+}
+
+func (tn *TestNode) connectNetwork(ctx context.Context, networkID string) error {
+	return tn.Pool.Client.ConnectNetwork(networkID, docker.NetworkConnectionOptions{
+		Container:      tn.Container.ID,
+		EndpointConfig: &docker.EndpointConfig{},
+		Context:        ctx,
+	})
+}
+
+// VotingPower returns the total voting power of the validators among the nodes
+func (tn TestNodes) VotingPower(ctx context.Context) (int64, error) {
+	var power int64
+	for _, n := range tn {
+		if !n.Validator {
+			continue
+		}
+		stat, err := n.Client.Status(ctx)
+		if err != nil {
+			return 0, err
+		}
+		power += stat.ValidatorInfo.VotingPower
+	}
+	return power, nil
+}
+
+// RequireHalted checks that none of the nodes that can be reached commits a block during the window
+func (tn TestNodes) RequireHalted(ctx context.Context, window time.Duration) error {
+	start := make(map[*TestNode]int64)
+	for _, n := range tn {
+		if height, err := n.Height(ctx); err == nil {
+			start[n] = height
+		}
+	}
+	if len(start) == 0 {
+		return fmt.Errorf("none of the nodes can be reached")
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(window):
+	}
+
+	for n, before := range start {
+		height, err := n.Height(ctx)
+		if err != nil {
+			continue
+		}
+		if height > before {
+			return fmt.Errorf("%s committed blocks %d to %d while the chain should be halted", n.Name(), before+1, height)
+		}
+	}
+	return nil
+}
+
+func TestPartitionHaltsChainAndRelayerRecovers(t *testing.T) {
+	gaia, err := GetChain("gaia", "")
+	require.NoError(t, err)
+
+	ctx, home, pool, network, chains := SetupTestChains(t,
+		ChainConfig{ChainType: gaia, NumValidators: 4, NumFullNodes: 1},
+		ChainConfig{ChainType: gaia, NumValidators: 1},
+	)
+	chainA, chainB := chains[0], chains[1]
+	StartChains(t, ctx, network, chains...)
+
+	relayer := NewCosmosRelayer(t, home, pool, network.ID)
+	SetupRelayer(t, ctx, relayer, "partition-path", chainA, chainB)
+	require.NoError(t, relayer.StartRelayer(ctx, "partition-path"))
+	t.Cleanup(func() { _ = relayer.StopRelayer(ctx) })
+
+	// neither side of the partition has more than 2/3 of the voting power
+	v := chainA.Validators
+	partition, err := PartitionNetwork(ctx, append(TestNodes{v[0], v[1]}, chainA.FullNodes...), TestNodes{v[2], v[3]})
+	require.NoError(t, err)
+	require.NoError(t, chainA.Nodes().RequireHalted(ctx, 20*time.Second))
+
+	// a packet sent to the halted chain can not be received until the partition heals
+	src, dst := chainB.Validators[0], chainA.Validators[0]
+	receiver, err := dst.AccountAddress(valKey)
+	require.NoError(t, err)
+	amount := sdk.NewInt64Coin(chainB.Chain.Denom, 1000)
+	require.NoError(t, src.SendIBCTransfer(ctx, "channel-0", valKey, receiver, amount))
+	packet, err := src.LatestSentPacket(ctx, "transfer", "channel-0")
+	require.NoError(t, err)
+
+	require.NoError(t, partition.Heal(ctx))
+	require.NoError(t, chainA.Nodes().CheckConsensus(ctx, 3, 2*time.Minute))
+
+	ack, err := WaitForPacketAck(ctx, src, dst, packet, 2*time.Minute)
+	require.NoError(t, err)
+	require.True(t, ack.Success(), ack.Error)
+}
@@ -0,0 +1,172 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ory/dockertest"
+	"github.com/ory/dockertest/docker"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	relayerKey          = "relayer"
+	relayerWalletAmount = 10000000
+)
+
+// DockerRelayer runs the jobs and the daemon of a relayer implementation in docker
+// containers attached to the test network
+type DockerRelayer struct {
+	Kind          string
+	Home          string
+	ContainerHome string
+	Repository    string
+	Version       string
+	Env           []string
+	Pool          *dockertest.Pool
+	NetworkID     string
+	Container     *docker.Container
+	t             *testing.T
+}
+
+// NewDockerRelayer creates the docker plumbing for a relayer and its home directory
+func NewDockerRelayer(t *testing.T, kind, home, containerHome, repository, version string,
+	pool *dockertest.Pool, networkID string) *DockerRelayer {
+	dr := &DockerRelayer{Kind: kind, Home: home, ContainerHome: containerHome,
+		Repository: repository, Version: version, Pool: pool, NetworkID: networkID, t: t}
+	dr.MkDir()
+	return dr
+}
+
+// Name is the hostname of the relayer container
+func (dr *DockerRelayer) Name() string {
+	return Hostname(dr.Kind, dr.t)
+}
+
+// Dir is the directory where the relayer files are stored
+func (dr *DockerRelayer) Dir() string {
+	return fmt.Sprintf("%s/%s/", dr.Home, dr.Name())
+}
+
+// MkDir creates the directory for the relayer
+func (dr *DockerRelayer) MkDir() {
+	if err := os.MkdirAll(dr.Dir(), 0755); err != nil {
+		panic(err)
+	}
+}
+
+// Bind returns the home folder bind point for running the relayer
+func (dr *DockerRelayer) Bind() []string {
+	return []string{fmt.Sprintf("%s:%s", dr.Dir(), dr.ContainerHome)}
+}
+
+// Image returns the docker image for the relayer
+func (dr *DockerRelayer) Image() string {
+	return fmt.Sprintf("%s:%s", dr.Repository, dr.Version)
+}
+
+// RelayerJob runs a container for a specific relayer command, blocks until the container exits
+// and returns the stdout and stderr of the command along with its exit code
+// NOTE: on job containers generate random name
+func (dr *DockerRelayer) RelayerJob(ctx context.Context, cmd []string) (JobResult, error) {
+	container := RandLowerCaseLetterString(10)
+	dr.t.Logf("{%s}[%s] -> '%s'", dr.Name(), container, strings.Join(cmd, " "))
+	res, err := runJobContainer(ctx, dr.Pool, docker.CreateContainerOptions{
+		Name:       container,
+		Config:     dr.containerConfig(container, cmd),
+		HostConfig: &docker.HostConfig{Binds: dr.Bind()},
+		NetworkingConfig: &docker.NetworkingConfig{
+			EndpointsConfig: map[string]*docker.EndpointConfig{
+				dr.NetworkID: {},
+			},
+		},
+		Context: nil,
+	})
+	if err == nil && res.ExitCode != 0 {
+		dr.t.Logf("{%s}[%s] exited with code %d\nstdout:\n%s\nstderr:\n%s",
+			dr.Name(), container, res.ExitCode, res.Stdout, res.Stderr)
+	}
+	return res, err
+}
+
+// StartRelayerContainer creates and starts the long running relayer container with the given command
+func (dr *DockerRelayer) StartRelayerContainer(cmd []string) error {
+	cont, err := dr.Pool.Client.CreateContainer(docker.CreateContainerOptions{
+		Name:   dr.Name(),
+		Config: dr.containerConfig(dr.Name(), cmd),
+		HostConfig: &docker.HostConfig{
+			Binds:      dr.Bind(),
+			AutoRemove: true,
+		},
+		NetworkingConfig: &docker.NetworkingConfig{
+			EndpointsConfig: map[string]*docker.EndpointConfig{
+				dr.NetworkID: {},
+			},
+		},
+		Context: nil,
+	})
+	if err != nil {
+		return err
+	}
+	dr.Container = cont
+	dr.t.Logf("{%s} => starting relayer '%s'", dr.Name(), strings.Join(cmd, " "))
+	return dr.Pool.Client.StartContainer(cont.ID, nil)
+}
+
+// StopRelayerContainer stops the long running relayer container if it is running
+func (dr *DockerRelayer) StopRelayerContainer(ctx context.Context) error {
+	if dr.Container == nil {
+		return nil
+	}
+	if err := dr.Pool.Client.StopContainerWithContext(dr.Container.ID, 30, ctx); err != nil {
+		return err
+	}
+	// the container is auto removed, wait for that to finish so the name can be reused
+	_, _ = dr.Pool.Client.WaitContainerWithContext(dr.Container.ID, ctx)
+	dr.Container = nil
+	return nil
+}
+
+// SetupRelayer creates and funds a relayer wallet on both chains, configures the relayer for the chains
+// and links a transfer channel between them on the given path
+func SetupRelayer(t *testing.T, ctx context.Context, relayer Relayer, pathName string, chainA, chainB *TestChain) {
+	_, mnemonic, err := chainA.Validators[0].CreateKeyWithMnemonic(relayerKey)
+	require.NoError(t, err)
+
+	for _, chain := range []*TestChain{chainA, chainB} {
+		require.NoError(t, FundRelayerWallet(ctx, chain, mnemonic, sdk.NewInt64Coin(chain.Chain.Denom, relayerWalletAmount)))
+		require.NoError(t, relayer.AddChainConfiguration(ctx, chain))
+		require.NoError(t, relayer.RestoreKey(ctx, chain.ChainID, mnemonic))
+	}
+
+	require.NoError(t, relayer.GeneratePath(ctx, chainA.ChainID, chainB.ChainID, pathName))
+	require.NoError(t, relayer.LinkPath(ctx, pathName))
+}
+
+// FundRelayerWallet sends coins from the chain's first validator to the account of the relayer mnemonic
+func FundRelayerWallet(ctx context.Context, chain *TestChain, mnemonic string, amount sdk.Coin) error {
+	addr, err := AddressFromMnemonic(mnemonic, chain.Chain.Bech32Prefix)
+	if err != nil {
+		return err
+	}
+	return chain.Validators[0].BankSend(ctx, valKey, addr, amount.String())
+}
+
+// containerConfig uses the first element of the command as the entrypoint so that relayer
+// images behave the same regardless of the entrypoint they were built with
+func (dr *DockerRelayer) containerConfig(hostname string, cmd []string) *docker.Config {
+	return &docker.Config{
+		User:       getDockerUserString(),
+		Hostname:   hostname,
+		DNS:        []string{},
+		Image:      dr.Image(),
+		Entrypoint: cmd[:1],
+		Cmd:        cmd[1:],
+		Env:        dr.Env,
+		Labels:     testLabels(dr.t),
+	}
+}
@@ -1,17 +1,16 @@
 package test
 
 import (
-	"os"
+	"context"
 	"testing"
+	"time"
 
-	"github.com/ory/dockertest"
-	"github.com/ory/dockertest/docker"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
 )
 
 func TestChainSpinUp(t *testing.T) {
-	ctx, home, pool, network, validators := SetupTestRun(t, 4)
-
-	t.Cleanup(Cleanup(pool, t.Name(), home))
+	ctx, _, _, network, validators := SetupTestRun(t, 4)
 
 	// start validators and sentry nodes
 	StartNodeContainers(t, ctx, network, validators, validators)
@@ -20,25 +19,83 @@ func TestChainSpinUp(t *testing.T) {
 	validators.WaitForHeight(5)
 }
 
-// Cleanup will clean up Docker containers, networks, and the other various config files generated in testing
-func Cleanup(pool *dockertest.Pool, testName, testDir string) func() {
-	return func() {
-		cont, _ := pool.Client.ListContainers(docker.ListContainersOptions{All: true})
-		for _, c := range cont {
-			for k, v := range c.Labels {
-				if k == "horcrux-test" && v == testName {
-					_ = pool.Client.StopContainer(c.ID, 10)
-				}
-			}
-		}
-		nets, _ := pool.Client.ListNetworks()
-		for _, n := range nets {
-			for k, v := range n.Labels {
-				if k == "horcrux-test" && v == testName {
-					_ = pool.Client.RemoveNetwork(n.ID)
-				}
-			}
-		}
-		_ = os.RemoveAll(testDir)
+func TestMultipleChainSpinUp(t *testing.T) {
+	gaia, err := GetChain("gaia", "")
+	require.NoError(t, err)
+
+	ctx, _, _, network, chains := SetupTestChains(t,
+		ChainConfig{ChainType: gaia, NumValidators: 1, NumFullNodes: 1},
+		ChainConfig{ChainType: gaia, NumValidators: 1, NumFullNodes: 1},
+	)
+
+	StartChains(t, ctx, network, chains...)
+
+	for _, c := range chains {
+		c.Nodes().WaitForHeight(5)
 	}
 }
+
+func TestRelayerCompatibility(t *testing.T) {
+	CompatibilityMatrix{
+		ChainA:         "gaia",
+		ChainAVersions: []string{"v6.0.0-rocks"},
+		ChainB:         "gaia",
+		ChainBVersions: []string{"v6.0.0-rocks"},
+	}.Run(t, func(t *testing.T, ctx context.Context, relayer Relayer, pathName string, chainA, chainB *TestChain) {
+		src, dst := chainA.Validators[0], chainB.Validators[0]
+		receiver, err := dst.AccountAddress(valKey)
+		require.NoError(t, err)
+
+		amount := sdk.NewInt64Coin(chainA.Chain.Denom, 1000)
+		require.NoError(t, src.SendIBCTransfer(ctx, "channel-0", valKey, receiver, amount))
+		packet, err := src.LatestSentPacket(ctx, "transfer", "channel-0")
+		require.NoError(t, err)
+		require.NoError(t, relayer.FlushPackets(ctx, pathName, "channel-0"))
+
+		ack, err := WaitForPacketAck(ctx, src, dst, packet, time.Minute)
+		require.NoError(t, err)
+		require.True(t, ack.Success(), ack.Error)
+
+		balance, err := dst.GetIBCBalance(ctx, "channel-0", receiver, amount.Denom)
+		require.NoError(t, err)
+		require.Equal(t, amount.Amount, balance.Amount)
+		src.RequireEscrowBalance(ctx, "channel-0", amount)
+	})
+}
+
+func TestTransferTimeoutRefund(t *testing.T) {
+	CompatibilityMatrix{
+		ChainA:         "gaia",
+		ChainAVersions: []string{"v6.0.0-rocks"},
+		ChainB:         "gaia",
+		ChainBVersions: []string{"v6.0.0-rocks"},
+	}.Run(t, func(t *testing.T, ctx context.Context, relayer Relayer, pathName string, chainA, chainB *TestChain) {
+		src, dst := chainA.Validators[0], chainB.Validators[0]
+		sender, err := src.AccountAddress(valKey)
+		require.NoError(t, err)
+		receiver, err := dst.AccountAddress(valKey)
+		require.NoError(t, err)
+
+		amount := sdk.NewInt64Coin(chainA.Chain.Denom, 1000)
+		require.NoError(t, src.SendIBCTransferWithTimeout(ctx, "channel-0", valKey, receiver, amount,
+			TransferTimeout{Timestamp: 10 * time.Second}))
+		src.RequireEscrowBalance(ctx, "channel-0", amount)
+
+		sent, err := src.GetBalance(ctx, sender, amount.Denom)
+		require.NoError(t, err)
+		packet, err := src.LatestSentPacket(ctx, "transfer", "channel-0")
+		require.NoError(t, err)
+
+		// the relayer stays stopped until the packet can no longer be received
+		require.NoError(t, dst.WaitForPacketExpiry(ctx, packet, time.Minute))
+		require.NoError(t, relayer.StartRelayer(ctx, pathName))
+		t.Cleanup(func() { _ = relayer.StopRelayer(ctx) })
+
+		require.NoError(t, WaitForPacketTimeout(ctx, src, dst, packet, 2*time.Minute))
+
+		refunded, err := src.GetBalance(ctx, sender, amount.Denom)
+		require.NoError(t, err)
+		require.True(t, sent.Add(amount).IsEqual(refunded), "sender has %s after refund, expected %s", refunded, sent.Add(amount))
+		src.RequireEscrowBalance(ctx, "channel-0", sdk.NewInt64Coin(amount.Denom, 0))
+	})
+}
@@ -5,24 +5,157 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net"
+	"os"
 	"testing"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/ory/dockertest"
 	"github.com/ory/dockertest/docker"
+	"github.com/strangelove-ventures/ibc-test-framework/reaper"
 	"github.com/stretchr/testify/require"
 )
 
+// SetupTestRun creates the test nodes of a gaia chain, the docker resources and the home folder
+// are cleaned up automatically when the test finishes
 func SetupTestRun(t *testing.T, numNodes int) (context.Context, string, *dockertest.Pool, *docker.Network, TestNodes) {
+	return SetupTestRunWithChain(t, "gaia", "", numNodes)
+}
+
+// SetupTestRunWithChain is SetupTestRun for the registered chain type with the given name and version
+func SetupTestRunWithChain(t *testing.T, chainName, version string, numNodes int) (context.Context, string, *dockertest.Pool, *docker.Network, TestNodes) {
+	chainType, err := GetChain(chainName, version)
+	require.NoError(t, err)
+
+	home, pool, network := setupTestEnv(t)
+
+	return context.Background(), home, pool, network, MakeTestNodes(numNodes, home, "ibc-test-framework", chainType, pool, t)
+}
+
+// SetupTestChains creates the test nodes for several independent chains that share one docker network,
+// each chain gets a unique chain id unless one is given in its config
+func SetupTestChains(t *testing.T, configs ...ChainConfig) (context.Context, string, *dockertest.Pool, *docker.Network, []*TestChain) {
+	home, pool, network := setupTestEnv(t)
+
+	chainIDs := make(map[string]bool)
+	chains := make([]*TestChain, len(configs))
+	for i, cfg := range configs {
+		chainID := cfg.ChainID
+		if chainID == "" {
+			chainID = fmt.Sprintf("%s-%d", cfg.ChainType.Name, i+1)
+		}
+		require.False(t, chainIDs[chainID], "duplicate chain id %s", chainID)
+		chainIDs[chainID] = true
+		require.Greater(t, cfg.NumValidators, 0, "chain %s needs at least one validator", chainID)
+
+		nodeChainTypes := make([]*ChainType, cfg.NumValidators+cfg.NumFullNodes)
+		for j := range nodeChainTypes {
+			nodeChainTypes[j] = cfg.ChainType
+			if ct, ok := cfg.NodeChainTypes[j]; ok {
+				nodeChainTypes[j] = ct
+			}
+		}
+		nodes := MakeTestNodesWithChainTypes(nodeChainTypes, home, chainID, pool, t)
+		for _, n := range nodes {
+			n.chainAppConfig = cfg.AppConfig
+			n.TMConfigMutators = append([]TMConfigMutator{}, cfg.TMConfigMutators...)
+			n.LogOutput = cfg.LogNodeOutput
+			n.Cosmovisor = cfg.Cosmovisor
+		}
+		hooks := cfg.GenesisHooks
+		if cfg.VotingPeriod != 0 {
+			hooks.PreCollectGentxs = append([]GenesisHook{SetGovVotingPeriod(cfg.VotingPeriod)}, hooks.PreCollectGentxs...)
+		}
+		chains[i] = &TestChain{
+			ChainID:      chainID,
+			Chain:        cfg.ChainType,
+			Validators:   nodes[:cfg.NumValidators:cfg.NumValidators],
+			FullNodes:    nodes[cfg.NumValidators:],
+			GenesisHooks: hooks,
+		}
+	}
+
+	return context.Background(), home, pool, network, chains
+}
+
+// StartChains starts the node containers for each of the chains on the given network
+func StartChains(t *testing.T, ctx context.Context, net *docker.Network, chains ...*TestChain) {
+	for _, c := range chains {
+		c.Start(t, ctx, net)
+	}
+}
+
+// Start bootstraps the chain's genesis and starts its validator and full node containers
+func (tc *TestChain) Start(t *testing.T, ctx context.Context, net *docker.Network) {
+	StartNodeContainersWithGenesisHooks(t, ctx, net, tc.Validators, tc.FullNodes, tc.GenesisHooks)
+}
+
+// Nodes returns the validators followed by the full nodes of the chain
+func (tc *TestChain) Nodes() TestNodes {
+	nodes := append(TestNodes{}, tc.Validators...)
+	return append(nodes, tc.FullNodes...)
+}
+
+// AddGenesisWallet creates a key in the first validator's keyring and funds its account at genesis,
+// it must be called before the chain is started
+func (tc *TestChain) AddGenesisWallet(keyName string, coins sdk.Coins) (address, mnemonic string, err error) {
+	info, mnemonic, err := tc.Validators[0].CreateKeyWithMnemonic(keyName)
+	if err != nil {
+		return "", "", err
+	}
+	address, err = sdk.Bech32ifyAddressBytes(tc.Chain.Bech32Prefix, info.GetAddress())
+	if err != nil {
+		return "", "", err
+	}
+	tc.GenesisHooks.PreCollectGentxs = append(tc.GenesisHooks.PreCollectGentxs, FundGenesisAccount(address, coins))
+	return address, mnemonic, nil
+}
+
+// RelayerNode returns the node relayers connect to, preferring a full node over a validator
+func (tc *TestChain) RelayerNode() *TestNode {
+	if len(tc.FullNodes) > 0 {
+		return tc.FullNodes[0]
+	}
+	return tc.Validators[0]
+}
+
+// RunID identifies the resources created by this go test run, it is used by the reaper
+// to clean up after runs that crashed before their cleanup ran
+var RunID = reaper.NewRunID()
+
+// testLabels returns the labels set on every docker resource created by a test
+func testLabels(t *testing.T) map[string]string {
+	return reaper.Labels(RunID, t.Name())
+}
+
+// Cleanup will clean up Docker containers, networks, volumes and the other various config files generated in testing,
+// failures to remove a resource are reported as test errors
+func Cleanup(t *testing.T, pool *dockertest.Pool, testDir string) func() {
+	return func() {
+		res, err := reaper.Reap(pool.Client, reaper.Filter{RunID: RunID, TestName: t.Name()})
+		if err != nil {
+			t.Errorf("cleanup of %s failed: %v", t.Name(), err)
+		}
+		t.Logf("cleanup removed %d containers, %d networks and %d volumes",
+			len(res.Containers), len(res.Networks), len(res.Volumes))
+		if err := os.RemoveAll(testDir); err != nil {
+			t.Errorf("failed to remove %s: %v", testDir, err)
+		}
+	}
+}
+
+func setupTestEnv(t *testing.T) (string, *dockertest.Pool, *docker.Network) {
 	home, err := ioutil.TempDir("", "")
 	require.NoError(t, err)
 
 	pool, err := dockertest.NewPool("")
 	require.NoError(t, err)
 
+	t.Cleanup(Cleanup(t, pool, home))
+
 	network, err := CreateTestNetwork(pool, fmt.Sprintf("ibc-test-framework-%s", RandLowerCaseLetterString(8)), t)
 	require.NoError(t, err)
 
-	return context.Background(), home, pool, network, MakeTestNodes(numNodes, home, "ibc-test-framework", getGaiadChain(), pool, t)
+	return home, pool, network
 }
 
 // GetHostPort returns a resource's published port with an address.
@@ -47,7 +180,7 @@ func CreateTestNetwork(pool *dockertest.Pool, name string, t *testing.T) (*docke
 	return pool.Client.CreateNetwork(docker.CreateNetworkOptions{
 		Name:           name,
 		Options:        map[string]interface{}{},
-		Labels:         map[string]string{"horcrux-test": t.Name()},
+		Labels:         testLabels(t),
 		CheckDuplicate: true,
 		Internal:       false,
 		EnableIPv6:     false,
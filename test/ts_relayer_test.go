@@ -0,0 +1,181 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ory/dockertest"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	tsRelayerRepository = "confio/ts-relayer"
+	tsRelayerVersion    = "0.4.0"
+	tsRelayerHome       = "/home/ts-relayer"
+)
+
+// TsRelayer is the confio/ts-relayer implementation of Relayer
+// NOTE: ts-relayer relays between the two chains of its app.yaml, so every path gets its own home folder
+type TsRelayer struct {
+	*DockerRelayer
+	chains    map[string]*TestChain
+	mnemonics map[string]string
+}
+
+var _ Relayer = (*TsRelayer)(nil)
+
+// tsRelayerRegistry is the registry.yaml consumed by ibc-setup and ibc-relayer
+type tsRelayerRegistry struct {
+	Version int                       `yaml:"version"`
+	Chains  map[string]tsRelayerChain `yaml:"chains"`
+}
+
+type tsRelayerChain struct {
+	ChainID              string   `yaml:"chain_id"`
+	Prefix               string   `yaml:"prefix"`
+	GasPrice             string   `yaml:"gas_price"`
+	HDPath               string   `yaml:"hd_path"`
+	ICS20Port            string   `yaml:"ics20_port"`
+	EstimatedBlockTime   int      `yaml:"estimated_block_time"`
+	EstimatedIndexerTime int      `yaml:"estimated_indexer_time"`
+	RPC                  []string `yaml:"rpc"`
+}
+
+// tsRelayerApp is the app.yaml of a path, ibc-setup fills in the connections when linking
+type tsRelayerApp struct {
+	Src            string `yaml:"src"`
+	Dest           string `yaml:"dest"`
+	Mnemonic       string `yaml:"mnemonic"`
+	SrcConnection  string `yaml:"srcConnection,omitempty"`
+	DestConnection string `yaml:"destConnection,omitempty"`
+}
+
+// NewTsRelayer creates a ts-relayer whose containers are attached to the given network
+func NewTsRelayer(t *testing.T, home string, pool *dockertest.Pool, networkID string) *TsRelayer {
+	return &TsRelayer{
+		DockerRelayer: NewDockerRelayer(t, "tsrelayer", home, tsRelayerHome, tsRelayerRepository, tsRelayerVersion, pool, networkID),
+		chains:        make(map[string]*TestChain),
+		mnemonics:     make(map[string]string),
+	}
+}
+
+// PathDir returns the host folder holding the registry.yaml and app.yaml for a path
+func (r *TsRelayer) PathDir(pathName string) string {
+	return path.Join(r.Dir(), pathName)
+}
+
+// PathHome returns the container folder holding the registry.yaml and app.yaml for a path
+func (r *TsRelayer) PathHome(pathName string) string {
+	return path.Join(tsRelayerHome, pathName)
+}
+
+// WriteRegistry writes the registry.yaml for a path from the configured chains
+func (r *TsRelayer) WriteRegistry(pathName string) error {
+	registry := tsRelayerRegistry{Version: 1, Chains: make(map[string]tsRelayerChain)}
+	for chainID, chain := range r.chains {
+		registry.Chains[chainID] = tsRelayerChain{
+			ChainID:              chainID,
+			Prefix:               chain.Chain.Bech32Prefix,
+			GasPrice:             chain.Chain.GasPrices,
+			HDPath:               sdk.FullFundraiserPath,
+			ICS20Port:            "transfer",
+			EstimatedBlockTime:   3000,
+			EstimatedIndexerTime: 500,
+			RPC:                  []string{fmt.Sprintf("http://%s:26657", chain.RelayerNode().Name())},
+		}
+	}
+	return writeYAML(path.Join(r.PathDir(pathName), "registry.yaml"), registry)
+}
+
+// ReadApp reads the app.yaml for a path
+func (r *TsRelayer) ReadApp(pathName string) (app tsRelayerApp, err error) {
+	bz, err := ioutil.ReadFile(path.Join(r.PathDir(pathName), "app.yaml"))
+	if err != nil {
+		return app, err
+	}
+	return app, yaml.Unmarshal(bz, &app)
+}
+
+// AddChainConfiguration implements Relayer
+func (r *TsRelayer) AddChainConfiguration(ctx context.Context, chain *TestChain) error {
+	r.chains[chain.ChainID] = chain
+	return nil
+}
+
+// RestoreKey implements Relayer, ts-relayer signs for both chains of a path with the same mnemonic
+// which is written to the app.yaml of every path using the chain
+func (r *TsRelayer) RestoreKey(ctx context.Context, chainID, mnemonic string) error {
+	if _, ok := r.chains[chainID]; !ok {
+		return fmt.Errorf("chain %s is not configured for ts-relayer", chainID)
+	}
+	r.mnemonics[chainID] = mnemonic
+	return nil
+}
+
+// GeneratePath implements Relayer
+func (r *TsRelayer) GeneratePath(ctx context.Context, srcChainID, dstChainID, pathName string) error {
+	for _, chainID := range []string{srcChainID, dstChainID} {
+		if _, ok := r.chains[chainID]; !ok {
+			return fmt.Errorf("chain %s is not configured for ts-relayer", chainID)
+		}
+	}
+	mnemonic := r.mnemonics[srcChainID]
+	if mnemonic == "" || mnemonic != r.mnemonics[dstChainID] {
+		return fmt.Errorf("ts-relayer needs the same key restored on %s and %s", srcChainID, dstChainID)
+	}
+	if err := os.MkdirAll(r.PathDir(pathName), 0755); err != nil {
+		return err
+	}
+	if err := r.WriteRegistry(pathName); err != nil {
+		return err
+	}
+	return writeYAML(path.Join(r.PathDir(pathName), "app.yaml"), tsRelayerApp{
+		Src:      srcChainID,
+		Dest:     dstChainID,
+		Mnemonic: mnemonic,
+	})
+}
+
+// LinkPath implements Relayer, ibc-setup creates the clients, connection and ics20 channel
+func (r *TsRelayer) LinkPath(ctx context.Context, pathName string) error {
+	command := []string{"ibc-setup", "ics20", "--home", r.PathHome(pathName)}
+	if err := handleNodeJobError(r.RelayerJob(ctx, command)); err != nil {
+		return err
+	}
+	app, err := r.ReadApp(pathName)
+	if err != nil {
+		return err
+	}
+	r.t.Logf("{%s} linked path %s with connections %s <-> %s", r.Name(), pathName, app.SrcConnection, app.DestConnection)
+	return nil
+}
+
+// StartRelayer implements Relayer
+func (r *TsRelayer) StartRelayer(ctx context.Context, pathName string) error {
+	return r.StartRelayerContainer([]string{"ibc-relayer", "start", "--home", r.PathHome(pathName), "--poll", "1"})
+}
+
+// StopRelayer implements Relayer
+func (r *TsRelayer) StopRelayer(ctx context.Context) error {
+	return r.StopRelayerContainer(ctx)
+}
+
+// FlushPackets implements Relayer, ts-relayer relays everything pending on the path's connection
+// so the channel is not needed
+func (r *TsRelayer) FlushPackets(ctx context.Context, pathName, channelID string) error {
+	command := []string{"ibc-relayer", "start", "--home", r.PathHome(pathName), "--once"}
+	return handleNodeJobError(r.RelayerJob(ctx, command))
+}
+
+func writeYAML(file string, v interface{}) error {
+	bz, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, bz, 0644) //nolint
+}
@@ -0,0 +1,261 @@
+package test
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/avast/retry-go"
+	"github.com/cosmos/cosmos-sdk/client"
+	clienttx "github.com/cosmos/cosmos-sdk/client/tx"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	txGasAdjustment    = 1.3
+	txInclusionTimeout = 30 * time.Second
+)
+
+// SendMsgs signs the messages with a key from the node's keyring, broadcasts them in a single transaction and
+// waits for it to be included in a block, returning the transaction response with an error if it failed,
+// messages of modules outside the sdk such as ibc-go MsgTransfer must be registered with RegisterInterfaces first
+func (tn *TestNode) SendMsgs(ctx context.Context, keyName string, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+	txf, err := tn.TxFactory(ctx, keyName)
+	if err != nil {
+		return nil, err
+	}
+
+	simRes, gas, err := clienttx.CalculateGas(tn.CliContext(), txf, msgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate tx on %s: %w", tn.Name(), err)
+	}
+	tn.t.Logf("{%s} simulated tx from %s using %d gas", tn.Name(), keyName, simRes.GasInfo.GasUsed)
+	txf = txf.WithGas(gas)
+
+	txBytes, err := signTx(tn.ec.TxConfig, txf, tn.Chain.Bech32Prefix, keyName, msgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := tn.CliContext().BroadcastTxSync(txBytes)
+	if err != nil {
+		return nil, err
+	}
+	if res.Code != 0 {
+		return res, fmt.Errorf("tx %s rejected by %s with code %d: %s", res.TxHash, tn.Name(), res.Code, res.RawLog)
+	}
+	return tn.WaitForTx(ctx, res.TxHash)
+}
+
+// bech32ConfigMu serializes the use of the global sdk bech32 config, which is switched to the prefix
+// of the chain a transaction is signed for
+var bech32ConfigMu sync.Mutex
+
+// withBech32Prefix runs fn with the global sdk config set to the bech32 prefixes of an account prefix,
+// the prefixes in use before are restored afterwards, fn must not convert addresses with String as
+// the sdk caches the converted addresses regardless of the prefix
+func withBech32Prefix(prefix string, fn func() error) error {
+	bech32ConfigMu.Lock()
+	defer bech32ConfigMu.Unlock()
+
+	config := sdk.GetConfig()
+	accAddr, accPub := config.GetBech32AccountAddrPrefix(), config.GetBech32AccountPubPrefix()
+	valAddr, valPub := config.GetBech32ValidatorAddrPrefix(), config.GetBech32ValidatorPubPrefix()
+	consAddr, consPub := config.GetBech32ConsensusAddrPrefix(), config.GetBech32ConsensusPubPrefix()
+	defer func() {
+		config.SetBech32PrefixForAccount(accAddr, accPub)
+		config.SetBech32PrefixForValidator(valAddr, valPub)
+		config.SetBech32PrefixForConsensusNode(consAddr, consPub)
+	}()
+
+	config.SetBech32PrefixForAccount(prefix, prefix+sdk.PrefixPublic)
+	config.SetBech32PrefixForValidator(prefix+sdk.PrefixValidator+sdk.PrefixOperator,
+		prefix+sdk.PrefixValidator+sdk.PrefixOperator+sdk.PrefixPublic)
+	config.SetBech32PrefixForConsensusNode(prefix+sdk.PrefixValidator+sdk.PrefixConsensus,
+		prefix+sdk.PrefixValidator+sdk.PrefixConsensus+sdk.PrefixPublic)
+	return fn()
+}
+
+// signTx builds and signs a transaction with the factory and returns its encoded bytes, the message
+// signers are decoded with the bech32 prefix of the chain the transaction is signed for, the sdk panics
+// on signers with another prefix which is returned as an error
+func signTx(txConfig client.TxConfig, txf clienttx.Factory, bech32Prefix, keyName string, msgs ...sdk.Msg) (txBytes []byte, err error) {
+	err = withBech32Prefix(bech32Prefix, func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("failed to sign tx for %s addresses: %v", bech32Prefix, r)
+			}
+		}()
+		txb, err := txf.BuildUnsignedTx(msgs...)
+		if err != nil {
+			return err
+		}
+		if err := clienttx.Sign(txf, keyName, txb, true); err != nil {
+			return err
+		}
+		txBytes, err = txConfig.TxEncoder()(txb.GetTx())
+		return err
+	})
+	return txBytes, err
+}
+
+// RegisterInterfaces registers the interfaces and messages of modules outside the sdk with the node's codec,
+// such as the RegisterInterfaces function of an ibc-go module, for example ibc transfer types before
+// sending a MsgTransfer with SendMsgs, the sdk modules including gov are registered already
+func (tn *TestNode) RegisterInterfaces(registrars ...func(codectypes.InterfaceRegistry)) {
+	for _, register := range registrars {
+		register(tn.ec.InterfaceRegistry)
+	}
+}
+
+// TxFactory returns a transaction factory that signs with a key from the node's keyring,
+// with the account number and sequence of the key's account on the node's chain
+func (tn *TestNode) TxFactory(ctx context.Context, keyName string) (clienttx.Factory, error) {
+	address, err := tn.AccountAddress(keyName)
+	if err != nil {
+		return clienttx.Factory{}, err
+	}
+	account, err := tn.GetAccount(ctx, address)
+	if err != nil {
+		return clienttx.Factory{}, err
+	}
+	return clienttx.Factory{}.
+		WithTxConfig(tn.ec.TxConfig).
+		WithKeybase(tn.Keybase()).
+		WithChainID(tn.ChainID).
+		WithGasAdjustment(txGasAdjustment).
+		WithGasPrices(tn.Chain.GasPrices).
+		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT).
+		WithAccountNumber(account.GetAccountNumber()).
+		WithSequence(account.GetSequence()), nil
+}
+
+// GetAccount queries the account of a bech32 address on the node's chain
+func (tn *TestNode) GetAccount(ctx context.Context, address string) (authtypes.AccountI, error) {
+	res, err := authtypes.NewQueryClient(tn.CliContext()).Account(ctx, &authtypes.QueryAccountRequest{Address: address})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account %s on %s: %w", address, tn.ChainID, err)
+	}
+	var account authtypes.AccountI
+	if err := tn.ec.InterfaceRegistry.UnpackAny(res.Account, &account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// WaitForTx waits for a transaction to be included in a block, returning the transaction response
+// with an error if the transaction failed
+func (tn *TestNode) WaitForTx(ctx context.Context, txHash string) (*sdk.TxResponse, error) {
+	hash, err := hex.DecodeString(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *sdk.TxResponse
+	err = retry.Do(func() error {
+		resTx, err := tn.Client.Tx(ctx, hash, false)
+		if err != nil {
+			return err
+		}
+		res = sdk.NewResponseResultTx(resTx, nil, "")
+		return nil
+	}, retry.Context(ctx), retry.Delay(time.Second), retry.DelayType(retry.FixedDelay),
+		retry.Attempts(uint(txInclusionTimeout/time.Second)), retry.LastErrorOnly(true))
+	if err != nil {
+		return nil, fmt.Errorf("tx %s not included on %s: %w", txHash, tn.ChainID, err)
+	}
+	if res.Code != 0 {
+		return res, fmt.Errorf("tx %s failed on %s with code %d: %s", txHash, tn.ChainID, res.Code, res.RawLog)
+	}
+	return res, nil
+}
+
+func TestSignTxWithChainPrefix(t *testing.T) {
+	ec := simapp.MakeTestEncodingConfig()
+	kr := keyring.NewInMemory()
+	info, _, err := kr.NewMnemonic("sender", keyring.English, sdk.FullFundraiserPath, "", hd.Secp256k1)
+	require.NoError(t, err)
+	sender, err := sdk.Bech32ifyAddressBytes("osmo", info.GetAddress())
+	require.NoError(t, err)
+
+	txf := clienttx.Factory{}.
+		WithTxConfig(ec.TxConfig).
+		WithKeybase(kr).
+		WithChainID("osmosis-1").
+		WithGas(200000).
+		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT)
+	txBytes, err := signTx(ec.TxConfig, txf, "osmo", "sender",
+		&banktypes.MsgSend{FromAddress: sender, ToAddress: sender, Amount: sdk.NewCoins(sdk.NewInt64Coin("uosmo", 1))},
+		&govtypes.MsgVote{ProposalId: 1, Voter: sender, Option: govtypes.OptionYes})
+	require.NoError(t, err)
+	require.NotEmpty(t, txBytes)
+	require.Equal(t, "cosmos", sdk.GetConfig().GetBech32AccountAddrPrefix())
+
+	_, err = signTx(ec.TxConfig, txf, "cosmos", "sender",
+		&banktypes.MsgSend{FromAddress: sender, ToAddress: sender, Amount: sdk.NewCoins(sdk.NewInt64Coin("uosmo", 1))})
+	require.Error(t, err)
+}
+
+func TestSendMsgs(t *testing.T) {
+	for _, name := range []string{"gaia", "osmosis"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			chainType, err := GetChain(name, "")
+			require.NoError(t, err)
+			testSendMsgs(t, chainType)
+		})
+	}
+}
+
+// testSendMsgs sends a bank and a staking message signed for the chain type's bech32 prefix
+func testSendMsgs(t *testing.T, chainType *ChainType) {
+	ctx, _, _, network, chains := SetupTestChains(t, ChainConfig{ChainType: chainType, NumValidators: 1})
+	chain := chains[0]
+	StartChains(t, ctx, network, chain)
+	node := chain.Validators[0]
+
+	sender, err := node.AccountAddress(valKey)
+	require.NoError(t, err)
+	_, _, err = node.CreateKeyWithMnemonic("delegator")
+	require.NoError(t, err)
+	delegator, err := node.AccountAddress("delegator")
+	require.NoError(t, err)
+
+	amount := sdk.NewInt64Coin(chainType.Denom, 1000000)
+	_, err = node.SendMsgs(ctx, valKey, &banktypes.MsgSend{FromAddress: sender, ToAddress: delegator, Amount: sdk.NewCoins(amount)})
+	require.NoError(t, err)
+	balance, err := node.GetBalance(ctx, delegator, chainType.Denom)
+	require.NoError(t, err)
+	require.True(t, balance.IsEqual(amount), "got %s, expected %s", balance, amount)
+
+	key, err := node.GetKey(valKey)
+	require.NoError(t, err)
+	valoper, err := sdk.Bech32ifyAddressBytes(chainType.Bech32Prefix+sdk.PrefixValidator+sdk.PrefixOperator, key.GetAddress())
+	require.NoError(t, err)
+	delegation := sdk.NewInt64Coin(chainType.Denom, 500000)
+	_, err = node.SendMsgs(ctx, "delegator", &stakingtypes.MsgDelegate{
+		DelegatorAddress: delegator,
+		ValidatorAddress: valoper,
+		Amount:           delegation,
+	})
+	require.NoError(t, err)
+	res, err := stakingtypes.NewQueryClient(node.CliContext()).Delegation(ctx, &stakingtypes.QueryDelegationRequest{
+		DelegatorAddr: delegator,
+		ValidatorAddr: valoper,
+	})
+	require.NoError(t, err)
+	require.True(t, res.DelegationResponse.Balance.IsEqual(delegation))
+}
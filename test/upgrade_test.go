@@ -0,0 +1,179 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/avast/retry-go"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+	"github.com/ory/dockertest/docker"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// haltConfirmation is how long a node's height must stay unchanged for its chain to be considered halted
+	haltConfirmation = 10 * time.Second
+	upgradeTimeout   = 5 * time.Minute
+)
+
+// Height returns the latest block height of the node
+func (tn *TestNode) Height(ctx context.Context) (int64, error) {
+	stat, err := tn.Client.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return stat.SyncInfo.LatestBlockHeight, nil
+}
+
+// WaitForHalt waits until the node has committed the halt height and stopped producing blocks,
+// a node whose rpc goes away after reaching the halt height is also considered halted
+func (tn *TestNode) WaitForHalt(ctx context.Context, haltHeight int64, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	var haltedSince time.Time
+	for {
+		height, err := tn.Height(ctx)
+		switch {
+		case err != nil && !haltedSince.IsZero():
+			return nil
+		case err != nil:
+		case height > haltHeight:
+			return fmt.Errorf("%s passed halt height %d: %d", tn.Name(), haltHeight, height)
+		case height == haltHeight && haltedSince.IsZero():
+			haltedSince = time.Now()
+		case height == haltHeight && time.Since(haltedSince) >= haltConfirmation:
+			tn.t.Logf("{%s} halted at height %d", tn.Name(), haltHeight)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for %s to halt at height %d (last height %d, err: %v)",
+				timeout, tn.Name(), haltHeight, height, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// RecreateContainer stops and removes the node container and starts a new one from the given chain type,
+// reusing the node's home folder and network
+func (tn *TestNode) RecreateContainer(ctx context.Context, chainType *ChainType) error {
+	if err := tn.StopContainer(); err != nil {
+		tn.t.Logf("{%s} stopping container: %v", tn.Name(), err)
+	}
+	err := tn.Pool.Client.RemoveContainer(docker.RemoveContainerOptions{ID: tn.Container.ID, Force: true})
+	if _, ok := err.(*docker.NoSuchContainer); err != nil && !ok {
+		tn.t.Logf("{%s} removing container: %v", tn.Name(), err)
+	}
+
+	tn.Chain = chainType
+	// an auto removed container may still be holding its name while it is removed
+	if err := retry.Do(func() error {
+		return tn.CreateNodeContainer(tn.NetworkID, true)
+	}, retry.Context(ctx), retry.Delay(time.Second), retry.DelayType(retry.FixedDelay)); err != nil {
+		return err
+	}
+	tn.t.Logf("{%s} => starting %s container...", tn.Name(), chainType.Image())
+	return tn.StartContainer(ctx)
+}
+
+// UpgradeNodes recreates the containers of every node of the chain from the given version of its chain type
+func (tc *TestChain) UpgradeNodes(ctx context.Context, version string) error {
+	chainType := *tc.Chain
+	chainType.Version = version
+	tc.Chain = &chainType
+
+	var eg errgroup.Group
+	for _, n := range tc.Nodes() {
+		n := n
+		eg.Go(func() error { return n.RecreateContainer(ctx, &chainType) })
+	}
+	return eg.Wait()
+}
+
+// Upgrade runs a software upgrade of the chain to a new version of its chain type, once the chain halts
+// at the upgrade height the nodes are restarted from the new version and the chain is expected
+// to resume producing blocks
+func (tc *TestChain) Upgrade(t *testing.T, ctx context.Context, upgradeName, version string, heightDelta int64) {
+	plan := tc.scheduleUpgrade(t, ctx, upgradeName, version, heightDelta)
+
+	t.Logf("{%s} waiting for upgrade %s at height %d", tc.ChainID, upgradeName, plan.Height)
+	var eg errgroup.Group
+	for _, n := range tc.Nodes() {
+		n := n
+		eg.Go(func() error { return n.WaitForHalt(ctx, plan.Height-1, upgradeTimeout) })
+	}
+	require.NoError(t, eg.Wait())
+
+	require.NoError(t, tc.UpgradeNodes(ctx, version))
+	tc.Nodes().WaitForHeight(plan.Height + 2)
+}
+
+// scheduleUpgrade schedules an upgrade heightDelta blocks from now through a governance proposal that
+// every validator votes for, so the voting period must end before then
+func (tc *TestChain) scheduleUpgrade(t *testing.T, ctx context.Context, upgradeName, version string,
+	heightDelta int64) upgradetypes.Plan {
+	proposer := tc.Validators[0]
+	height, err := proposer.Height(ctx)
+	require.NoError(t, err)
+	plan := upgradetypes.Plan{Name: upgradeName, Height: height + heightDelta}
+
+	deposit, err := proposer.MinDeposit(ctx)
+	require.NoError(t, err)
+	proposalID, err := proposer.SubmitSoftwareUpgradeProposal(ctx, valKey, upgradeName,
+		fmt.Sprintf("upgrade %s to %s", tc.ChainID, version), plan, deposit)
+	require.NoError(t, err)
+	require.NoError(t, tc.Validators.PassProposal(ctx, proposalID, upgradeTimeout))
+	t.Logf("{%s} scheduled upgrade %s at height %d", tc.ChainID, upgradeName, plan.Height)
+	return plan
+}
+
+func TestChainUpgradeRelays(t *testing.T) {
+	const (
+		upgradeName = "v7-Theta"
+		fromVersion = "v6.0.0-rocks"
+		toVersion   = "v7.0.0"
+	)
+	gaiaFrom, err := GetChain("gaia", fromVersion)
+	require.NoError(t, err)
+	gaia, err := GetChain("gaia", "")
+	require.NoError(t, err)
+
+	ctx, home, pool, network, chains := SetupTestChains(t,
+		ChainConfig{ChainType: gaiaFrom, NumValidators: 2, NumFullNodes: 1, VotingPeriod: 15 * time.Second},
+		ChainConfig{ChainType: gaia, NumValidators: 1},
+	)
+	chainA, chainB := chains[0], chains[1]
+	StartChains(t, ctx, network, chains...)
+
+	relayer := NewCosmosRelayer(t, home, pool, network.ID)
+	SetupRelayer(t, ctx, relayer, "upgrade-path", chainA, chainB)
+	require.NoError(t, relayer.StartRelayer(ctx, "upgrade-path"))
+	t.Cleanup(func() { _ = relayer.StopRelayer(ctx) })
+
+	src, dst := chainA.Validators[0], chainB.Validators[0]
+	receiver, err := dst.AccountAddress(valKey)
+	require.NoError(t, err)
+	amount := sdk.NewInt64Coin(chainA.Chain.Denom, 1000)
+	transfer := func() {
+		require.NoError(t, src.SendIBCTransfer(ctx, "channel-0", valKey, receiver, amount))
+		packet, err := src.LatestSentPacket(ctx, "transfer", "channel-0")
+		require.NoError(t, err)
+		ack, err := WaitForPacketAck(ctx, src, dst, packet, 2*time.Minute)
+		require.NoError(t, err)
+		require.True(t, ack.Success(), ack.Error)
+	}
+
+	transfer()
+	chainA.Upgrade(t, ctx, upgradeName, toVersion, 30)
+	transfer()
+
+	balance, err := dst.GetIBCBalance(ctx, "channel-0", receiver, amount.Denom)
+	require.NoError(t, err)
+	require.Equal(t, amount.Amount.MulRaw(2).Int64(), balance.Amount.Int64())
+}